@@ -0,0 +1,157 @@
+package radix
+
+import (
+	"math/bits"
+	"unicode/utf8"
+)
+
+// A bloom filter of the ordered letter pairs in the keys below a node. For
+// every key at or below the node, each pair of letters where the first comes
+// somewhere before the second (not only next to it) is set. The letters are
+// taken by the bit they set in the bit mask, so the pairs follow the mask
+// mode or mapper of the tree.
+//
+// The fuzzy search looks for the letters of the search in order, so a key
+// can only match if every ordered pair of letters in the search is set too.
+// The bit mask alone can't tell "som" from "mos"
+type bigramSignature [8]uint64
+
+// WithBigrams gives each node a bigram signature which the fuzzy search
+// checks alongside the bit mask, letting it skip branches which hold all the
+// letters searched for but not in the right order. It costs 64 bytes a node
+// and makes adding keys slower.
+//
+// Signatures aren't saved, they are rebuilt when a tree is read, and the
+// memory mapped format doesn't have them
+func WithBigrams() Option {
+	return func(options *treeOptions) {
+		options.bigrams = true
+	}
+}
+
+// Returns the bit of the signature holding the pair of letters, by the bits
+// they set in the bit mask
+func bigramBit(first, second int) (int, uint64) {
+
+	// Fibonacci hashing spreads the 4096 pairs over the 512 bits
+	hash := uint32(first<<6|second) * 2654435769 >> 23
+	return int(hash >> 6), 1 << (hash & 63)
+}
+
+// Sets the pair of letters
+func (sig *bigramSignature) set(first, second int) {
+	word, bit := bigramBit(first, second)
+	sig[word] |= bit
+}
+
+// Sets every pair of a letter in the first mask followed by one in the
+// second
+func (sig *bigramSignature) setAll(first, second uint64) {
+
+	for a := first; a != 0; a &= a - 1 {
+		for b := second; b != 0; b &= b - 1 {
+			sig.set(bits.TrailingZeros64(a), bits.TrailingZeros64(b))
+		}
+	}
+}
+
+// Adds every pair set in the other signature
+func (sig *bigramSignature) or(other *bigramSignature) {
+	for i := range sig {
+		sig[i] |= other[i]
+	}
+}
+
+// Checks whether every pair set in the other signature is set in this one
+func (sig *bigramSignature) contains(other *bigramSignature) bool {
+
+	for i := range sig {
+		if sig[i]&other[i] != other[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Generates the signature of a run of bytes, holding every ordered pair of
+// its letters. The letters are taken by the bit they set in the bit mask,
+// those which set no bit are left out
+func genBigrams(str []byte, masker bitMasker) bigramSignature {
+
+	var sig bigramSignature
+
+	// Searches are short, so this rarely needs to grow
+	var buf [32]int
+	letters := buf[:0]
+
+	for len(str) > 0 {
+
+		_, size := utf8.DecodeRune(str)
+
+		if mask := masker(str[:size]); mask != 0 {
+			letters = append(letters, bits.TrailingZeros64(mask))
+		}
+		str = str[size:]
+	}
+
+	for i, first := range letters {
+		for _, second := range letters[i+1:] {
+			sig.set(first, second)
+		}
+	}
+
+	return sig
+}
+
+// IsBigramSet checks whether the node may hold every pair of letters in the
+// signature. Nodes without a signature may hold anything
+func (rn *treeNode[V]) IsBigramSet(sig *bigramSignature) bool {
+	return rn.bigrams == nil || rn.bigrams.contains(sig)
+}
+
+// RebuildBigrams regenerates the signature from the node's own key and the
+// signatures of its children, like RebuildBitMask. Children without one are
+// new, so theirs are built first. A new signature is always made, as the old
+// one may be shared with a snapshot
+func (rn *treeNode[V]) RebuildBigrams(masker bitMasker) {
+
+	sig := genBigrams(rn.Key(), masker)
+	keyMask := masker(rn.Key())
+
+	for _, child := range rn.Children() {
+
+		if child.bigrams == nil {
+			child.RebuildBigrams(masker)
+		}
+
+		// Every letter of the key comes before every letter below it
+		sig.setAll(keyMask, child.BitMask())
+		sig.or(child.bigrams)
+	}
+
+	rn.bigrams = &sig
+}
+
+// Regenerates the signatures of the node and everything below it
+func (rn *treeNode[V]) rebuildAllBigrams(masker bitMasker) {
+
+	for _, child := range rn.Children() {
+		child.rebuildAllBigrams(masker)
+	}
+
+	rn.RebuildBigrams(masker)
+}
+
+// Regenerates the signatures along a path from the root after a change, the
+// path must be writable. The root's is never checked so it is left alone
+func (tree *Tree[V]) rebuildBigrams(path []*treeNode[V]) {
+
+	if !tree.options.bigrams {
+		return
+	}
+
+	for i := len(path) - 1; i > 0; i-- {
+		path[i].RebuildBigrams(tree.masker())
+	}
+}
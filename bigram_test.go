@@ -0,0 +1,172 @@
+package radix
+
+import (
+	"slices"
+	"testing"
+)
+
+// Counts the nodes the fuzzy search would descend into, those whose bit mask
+// and signature hold the letters of the string
+func countBigramMatches[V any](tree *Tree[V], str string) int {
+
+	searchBitMask := tree.masker()([]byte(str))
+	searchBigrams := genBigrams([]byte(str), tree.masker())
+	count := 0
+
+	var visit func(node *treeNode[V])
+	visit = func(node *treeNode[V]) {
+		for _, child := range node.Children() {
+			if child.IsBitMaskSet(searchBitMask) && child.IsBigramSet(&searchBigrams) {
+				count++
+				visit(child)
+			}
+		}
+	}
+	visit(tree.root)
+
+	return count
+}
+
+// Checks that every node's signature is the one it would be given if built
+// from scratch
+func checkBigrams[V any](tree *Tree[V], t *testing.T) {
+
+	var visit func(node *treeNode[V], path string)
+	visit = func(node *treeNode[V], path string) {
+		for _, child := range node.Children() {
+
+			childPath := path + string(child.Key())
+			visit(child, childPath)
+
+			expected := *child
+			expected.bigrams = nil
+			expected.RebuildBigrams(tree.masker())
+
+			if child.bigrams == nil || *child.bigrams != *expected.bigrams {
+				t.Errorf("Expected %s to have the signature %x, got %x",
+					childPath, *expected.bigrams, child.bigrams)
+			}
+		}
+	}
+	visit(tree.root, "")
+}
+
+func TestGenBigrams(t *testing.T) {
+
+	som := genBigrams([]byte("som"), genBitMask32)
+	mos := genBigrams([]byte("mos"), genBitMask32)
+	somerset := genBigrams([]byte("somerset"), genBitMask32)
+
+	if som == mos {
+		t.Errorf("Expected som and mos to have different signatures")
+	}
+	if !somerset.contains(&som) {
+		t.Errorf("Expected somerset to hold the pairs of som")
+	}
+	if somerset.contains(&mos) {
+		t.Errorf("Expected somerset not to hold the pairs of mos")
+	}
+
+	// Repeated letters only pair with themselves when they appear twice
+	ss := genBigrams([]byte("ss"), genBitMask32)
+	sa := genBigrams([]byte("sa"), genBitMask32)
+	if sa.contains(&ss) {
+		t.Errorf("Expected sa not to hold the pair ss")
+	}
+	if !somerset.contains(&ss) {
+		t.Errorf("Expected somerset to hold the pair ss")
+	}
+
+	empty := genBigrams([]byte("s"), genBitMask32)
+	if empty != (bigramSignature{}) {
+		t.Errorf("Expected a single letter to have no pairs, got %x", empty)
+	}
+}
+
+// The signatures should stay right as keys are added and removed, including
+// in snapshots
+func TestBigramsMaintained(t *testing.T) {
+
+	r := NewTree[int](WithBigrams())
+	keys := []string{
+		"romane", "romanus", "romulus", "rubens", "ruber", "rubicon",
+		"rubicundus", "rom", "tesorería", "tesorero",
+	}
+	for i, key := range keys {
+		r.Add(key, i)
+	}
+	checkBigrams(r, t)
+
+	snapshot := r.Snapshot()
+
+	r.Delete("romulus")
+	r.Delete("rom")
+	r.DeletePrefix("rubi")
+	r.Add("rubicante", 10)
+	checkBigrams(r, t)
+	checkBigrams(snapshot, t)
+
+	if found, _ := snapshot.FuzzySearch("rmls"); !slices.Equal(found, []string{"romulus"}) {
+		t.Errorf("Expected the snapshot to still find romulus, got %v", found)
+	}
+	if found, _ := r.FuzzySearch("rmls"); len(found) != 0 {
+		t.Errorf("Expected romulus to be gone, got %v", found)
+	}
+
+	built, err := BuildFromSorted(pairs(r.PrefixSearch("")), WithBigrams())
+	if err != nil {
+		t.Fatalf("Expected to build the tree, got %s", err)
+	}
+	checkBigrams(built, t)
+
+	data, err := r.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Expected to write the tree, got %s", err)
+	}
+	loaded := NewTree[int](WithBigrams())
+	if err := loaded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("Expected to read the tree, got %s", err)
+	}
+	checkBigrams(loaded, t)
+}
+
+// The signatures should only ever rule out branches with nothing to find,
+// and should rule out many which the bit masks can't
+func TestBigramsFuzzySearch(t *testing.T) {
+
+	r := buildIntegrationTree()
+	keys, content := r.PrefixSearch("")
+
+	withBigrams, err := BuildFromSorted(pairs(keys, content), WithBigrams())
+	if err != nil {
+		t.Fatalf("Expected to build the tree, got %s", err)
+	}
+
+	added := NewRadixTree(WithBigrams())
+	for i := len(keys) - 1; i >= 0; i-- {
+		added.Add(keys[i], content[i])
+	}
+
+	for _, search := range []string{"som", "somer", "mos", "rd, k", "ab", "tesco", "zzz"} {
+
+		expected, _ := r.FuzzySearch(search)
+
+		for _, tree := range []*RadixTree{withBigrams, added} {
+			found, _ := tree.FuzzySearch(search)
+			if !slices.Equal(expected, found) {
+				t.Errorf("Expected %s to find the same %d keys with bigrams, got %d",
+					search, len(expected), len(found))
+			}
+		}
+	}
+
+	for _, search := range []string{"som", "somer"} {
+
+		masked := countMaskMatches(r, search)
+		pruned := countBigramMatches(withBigrams, search)
+		if pruned >= masked {
+			t.Errorf("Expected %s to descend into fewer nodes with bigrams, got %d and %d",
+				search, pruned, masked)
+		}
+	}
+}
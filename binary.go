@@ -0,0 +1,512 @@
+package radix
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"hash"
+	"hash/crc32"
+	"io"
+	"slices"
+)
+
+// The serialised form starts with the magic bytes, the format version, the
+// mask mode and the normalisation, followed by the counts, then every node
+// depth first. Each node is written as its key, bit mask, flags, content (if
+// collected), the key as it was inserted (if normalising changed it) and
+// number of children. A CRC-32 of everything before it comes last
+const (
+	binaryMagic   = "RDXT"
+	binaryVersion = 1
+
+	// Set in the flags when the node is a key that was inserted
+	binaryCollect = 1 << 0
+
+	// Set in the flags when the key was normalised into something else
+	binaryOriginal = 1 << 1
+
+	// The longest length which is believed at all when reading
+	binaryMaxChunk = 1 << 30
+
+	// Long fields are read a piece at a time, so that a corrupt length runs
+	// out of data before a buffer of that length is allocated
+	binaryReadChunk = 64 << 10
+)
+
+var (
+	// ErrInvalidFormat is returned when reading data which isn't a
+	// serialised tree, or is cut short
+	ErrInvalidFormat = errors.New("Data is not a serialised tree")
+
+	// ErrUnsupportedVersion is returned when reading data written in a
+	// format version this package doesn't know
+	ErrUnsupportedVersion = errors.New("Unsupported serialised tree version")
+
+	// ErrChecksumMismatch is returned when the data has been corrupted
+	ErrChecksumMismatch = errors.New("Serialised tree checksum mismatch")
+)
+
+// Codec turns content into bytes and back again when serialising a tree
+type Codec[V any] interface {
+	Encode(content V) ([]byte, error)
+	Decode(data []byte) (V, error)
+}
+
+// GobCodec encodes content with encoding/gob, this is the default. Concrete
+// types held in an interface must be registered with gob.Register
+type GobCodec[V any] struct{}
+
+// Encode gob encodes the content
+func (GobCodec[V]) Encode(content V) ([]byte, error) {
+
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(&content)
+
+	return buf.Bytes(), err
+}
+
+// Decode gob decodes the content
+func (GobCodec[V]) Decode(data []byte) (V, error) {
+
+	var content V
+	err := gob.NewDecoder(bytes.NewReader(data)).Decode(&content)
+
+	return content, err
+}
+
+// JSONCodec encodes content with encoding/json
+type JSONCodec[V any] struct{}
+
+// Encode JSON encodes the content
+func (JSONCodec[V]) Encode(content V) ([]byte, error) {
+	return json.Marshal(content)
+}
+
+// Decode JSON decodes the content
+func (JSONCodec[V]) Decode(data []byte) (V, error) {
+
+	var content V
+	err := json.Unmarshal(data, &content)
+
+	return content, err
+}
+
+// SetCodec sets how content is encoded when the tree is serialised
+func (tree *Tree[V]) SetCodec(codec Codec[V]) {
+	tree.codec = codec
+}
+
+// Returns the codec to serialise content with
+func (tree *Tree[V]) contentCodec() Codec[V] {
+
+	if tree.codec == nil {
+		return GobCodec[V]{}
+	}
+
+	return tree.codec
+}
+
+// MarshalBinary serialises the tree, including the structure of its nodes
+// so that it doesn't need rebuilding when read back
+func (tree *Tree[V]) MarshalBinary() ([]byte, error) {
+
+	var buf bytes.Buffer
+	if _, err := tree.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary replaces the contents of the tree with serialised data,
+// which must hold the tree and nothing after it
+func (tree *Tree[V]) UnmarshalBinary(data []byte) error {
+	_, err := tree.readFrom(bytes.NewReader(data), int64(len(data)))
+	return err
+}
+
+// WriteTo serialises the tree to the writer, returning the number of bytes
+// written
+func (tree *Tree[V]) WriteTo(w io.Writer) (int64, error) {
+
+	bw := &binaryWriter{
+		w:    bufio.NewWriter(w),
+		hash: crc32.NewIEEE(),
+	}
+
+	bw.write([]byte(binaryMagic))
+	bw.write([]byte{
+		binaryVersion,
+		byte(tree.options.savedMode()),
+		byte(tree.options.normalisation),
+	})
+	bw.writeUvarint(uint64(tree.stringCount))
+	bw.writeUvarint(uint64(tree.nodeCount))
+
+	tree.writeNode(bw, tree.root)
+
+	checksum := make([]byte, 4)
+	binary.LittleEndian.PutUint32(checksum, bw.hash.Sum32())
+	bw.write(checksum)
+
+	if bw.err == nil {
+		bw.err = bw.w.Flush()
+	}
+
+	return bw.count, bw.err
+}
+
+// Writes a node and everything below it
+func (tree *Tree[V]) writeNode(bw *binaryWriter, node *treeNode[V]) {
+
+	bw.writeUvarint(uint64(len(node.Key())))
+	bw.write(node.Key())
+
+	bw.writeUvarint(node.BitMask())
+
+	var flags byte
+	if node.Collect() {
+		flags |= binaryCollect
+	}
+	if node.original != "" {
+		flags |= binaryOriginal
+	}
+	bw.write([]byte{flags})
+
+	if node.Collect() && bw.err == nil {
+		content, err := tree.contentCodec().Encode(node.Content())
+		if err != nil {
+			bw.err = err
+			return
+		}
+		bw.writeUvarint(uint64(len(content)))
+		bw.write(content)
+	}
+
+	if node.original != "" {
+		bw.writeUvarint(uint64(len(node.original)))
+		bw.write([]byte(node.original))
+	}
+
+	bw.writeUvarint(uint64(len(node.Children())))
+	for _, child := range node.Children() {
+		tree.writeNode(bw, child)
+	}
+}
+
+// ReadFrom replaces the contents of the tree with serialised data read from
+// the reader, returning the number of bytes read. The tree is left as it
+// was if the data can't be read.
+//
+// Only the bytes of the tree are taken from the reader, so whatever follows
+// it is left to be read. A reader which isn't an io.ByteReader is read a
+// byte at a time in places, so one which is slow to read from is best
+// wrapped in a bufio.Reader
+func (tree *Tree[V]) ReadFrom(r io.Reader) (int64, error) {
+	return tree.readFrom(r, -1)
+}
+
+// Reads the serialised tree. If the size of the data is known (not
+// negative) the tree must be all of it, and no length can be longer than
+// what is left
+func (tree *Tree[V]) readFrom(r io.Reader, size int64) (int64, error) {
+
+	br := &binaryReader{
+		r:    toByteReader(r),
+		hash: crc32.NewIEEE(),
+		size: size,
+	}
+
+	header := br.read(len(binaryMagic) + 3)
+	if br.err != nil || string(header[:len(binaryMagic)]) != binaryMagic {
+		return br.count, ErrInvalidFormat
+	}
+	if header[len(binaryMagic)] != binaryVersion {
+		return br.count, ErrUnsupportedVersion
+	}
+
+	maskMode := MaskMode(header[len(binaryMagic)+1])
+	normalisation := Normalisation(header[len(binaryMagic)+2])
+
+	stringCount := br.readUvarint()
+	nodeCount := br.readUvarint()
+
+	// A generation of its own, the old nodes may be shared with snapshots
+	generation := nextGeneration()
+	root, keys, nodes := tree.readNode(br, generation)
+	if br.err != nil {
+		return br.count, br.err
+	}
+
+	expected := br.hash.Sum32()
+	checksum := br.read(4)
+	if br.err != nil {
+		return br.count, br.err
+	}
+	if binary.LittleEndian.Uint32(checksum) != expected {
+		return br.count, ErrChecksumMismatch
+	}
+
+	if size >= 0 {
+		if _, err := br.r.ReadByte(); err != io.EOF {
+			return br.count, ErrInvalidFormat
+		}
+	}
+
+	// The root itself isn't counted as a node
+	if uint64(keys) != stringCount || uint64(nodes-1) != nodeCount {
+		return br.count, ErrInvalidFormat
+	}
+
+	// Keys normalised another way wouldn't be found, so they are added again
+	// from the keys as they were first inserted
+	if normalisation != tree.options.normalisation {
+
+		renormalised := newTree[V](tree.options)
+		tree.walk(root, []byte{}, func(key []byte, node *treeNode[V]) bool {
+			renormalised.Add(node.originalKey(key), node.Content())
+			return true
+		})
+
+		tree.root = renormalised.root
+		tree.stringCount = renormalised.stringCount
+		tree.nodeCount = renormalised.nodeCount
+		tree.generation = renormalised.generation
+
+		return br.count, nil
+	}
+
+	// Masks generated another way would make the fuzzy search skip
+	// branches it shouldn't. There's no knowing whether masks made by a
+	// mapper were made by the same one
+	if maskMode != tree.options.savedMode() || maskMode == maskCustom {
+		for _, child := range root.Children() {
+			child.rebuildBitMasks(tree.masker())
+		}
+	}
+
+	if tree.options.bigrams {
+		for _, child := range root.Children() {
+			child.rebuildAllBigrams(tree.masker())
+		}
+	}
+
+	tree.root = root
+	tree.stringCount = keys
+	tree.nodeCount = nodes - 1
+	tree.generation = generation
+
+	return br.count, nil
+}
+
+// Reads a node and everything below it, returning the number of keys and
+// nodes which were read (including the node itself)
+func (tree *Tree[V]) readNode(
+	br *binaryReader,
+	generation uint64,
+) (*treeNode[V], int, int) {
+
+	node := &treeNode[V]{generation: generation}
+
+	node.key = br.read(br.readLength())
+
+	node.bitMask = br.readUvarint()
+
+	flags := br.read(1)
+	if br.err != nil {
+		return nil, 0, 0
+	}
+
+	keys, nodes := 0, 1
+
+	if flags[0]&binaryCollect != 0 {
+		content := br.read(br.readLength())
+		if br.err != nil {
+			return nil, 0, 0
+		}
+
+		decoded, err := tree.contentCodec().Decode(content)
+		if err != nil {
+			br.err = err
+			return nil, 0, 0
+		}
+
+		node.SetContent(decoded)
+		node.SetToCollect()
+		keys++
+	}
+
+	if flags[0]&binaryOriginal != 0 {
+		original := br.read(br.readLength())
+		if br.err != nil {
+			return nil, 0, 0
+		}
+		node.original = string(original)
+	}
+
+	numChildren := br.readLength()
+	for i := 0; i < numChildren && br.err == nil; i++ {
+
+		child, childKeys, childNodes := tree.readNode(br, generation)
+		if child == nil {
+			return nil, 0, 0
+		}
+
+		// Children must be in order for the searches to work, and only the
+		// root may have an empty key
+		if len(child.Key()) == 0 || (len(node.children) > 0 &&
+			bytes.Compare(node.children[len(node.children)-1].Key(), child.Key()) >= 0) {
+			br.err = ErrInvalidFormat
+			return nil, 0, 0
+		}
+
+		node.children = append(node.children, child)
+		keys += childKeys
+		nodes += childNodes
+	}
+
+	node.size = keys
+	return node, keys, nodes
+}
+
+// -----------------------------------------------------------------------------
+
+// Writes the serialised form while keeping a count and checksum of what was
+// written. The first error is kept and everything after it skipped
+type binaryWriter struct {
+	w     *bufio.Writer
+	hash  hash.Hash32
+	count int64
+	err   error
+}
+
+func (bw *binaryWriter) write(data []byte) {
+
+	if bw.err != nil {
+		return
+	}
+
+	n, err := bw.w.Write(data)
+	bw.hash.Write(data[:n])
+	bw.count += int64(n)
+	bw.err = err
+}
+
+func (bw *binaryWriter) writeUvarint(value uint64) {
+	buf := make([]byte, binary.MaxVarintLen64)
+	bw.write(buf[:binary.PutUvarint(buf, value)])
+}
+
+// Reads the serialised form while keeping a count and checksum of what was
+// read. The first error is kept and everything after it skipped
+type binaryReader struct {
+	r     byteReader
+	hash  hash.Hash32
+	count int64
+	size  int64
+	err   error
+}
+
+// Reads the length of bytes, in pieces so that what is allocated never runs
+// far ahead of what has actually been read
+func (br *binaryReader) read(length int) []byte {
+
+	if br.err != nil {
+		return nil
+	}
+
+	data := make([]byte, 0, min(length, binaryReadChunk))
+	for len(data) < length {
+
+		chunk := min(length-len(data), binaryReadChunk)
+		data = slices.Grow(data, chunk)
+
+		n, err := io.ReadFull(br.r, data[len(data):len(data)+chunk])
+		br.hash.Write(data[len(data) : len(data)+n])
+		br.count += int64(n)
+		data = data[:len(data)+n]
+
+		if err != nil {
+			br.err = ErrInvalidFormat
+			break
+		}
+	}
+
+	return data
+}
+
+// A reader which can also give a byte at a time, as varints are read
+type byteReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+// Reads single bytes straight from a reader, so that nothing past the end of
+// the tree is taken from it as buffering would
+type singleByteReader struct {
+	io.Reader
+}
+
+func (r singleByteReader) ReadByte() (byte, error) {
+
+	var b [1]byte
+	if _, err := io.ReadFull(r.Reader, b[:]); err != nil {
+		return 0, err
+	}
+
+	return b[0], nil
+}
+
+// Returns the reader able to read a byte at a time
+func toByteReader(r io.Reader) byteReader {
+
+	if br, ok := r.(byteReader); ok {
+		return br
+	}
+
+	return singleByteReader{r}
+}
+
+// Lets binary.ReadUvarint read through the checksum and count
+func (br *binaryReader) ReadByte() (byte, error) {
+
+	b, err := br.r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+
+	br.hash.Write([]byte{b})
+	br.count++
+
+	return b, nil
+}
+
+func (br *binaryReader) readUvarint() uint64 {
+
+	if br.err != nil {
+		return 0
+	}
+
+	value, err := binary.ReadUvarint(br)
+	if err != nil {
+		br.err = ErrInvalidFormat
+	}
+
+	return value
+}
+
+// Reads a length, which must be small enough to allocate and can't be
+// longer than what is left of the data when its size is known
+func (br *binaryReader) readLength() int {
+
+	length := br.readUvarint()
+	if length > binaryMaxChunk || (br.size >= 0 && length > uint64(br.size-br.count)) {
+		br.err = ErrInvalidFormat
+		return 0
+	}
+
+	return int(length)
+}
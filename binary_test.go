@@ -0,0 +1,289 @@
+package radix
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"io"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+// Content of the integration tree is always struct{}{}, which there is no
+// need to write out
+type emptyCodec struct{}
+
+func (emptyCodec) Encode(content interface{}) ([]byte, error) {
+	return nil, nil
+}
+
+func (emptyCodec) Decode(data []byte) (interface{}, error) {
+	return struct{}{}, nil
+}
+
+// Reading back a serialised tree should give the same structure, keys and
+// content
+func TestBinaryRoundTrip(t *testing.T) {
+
+	r := NewTree[int]()
+	for i, key := range []string{
+		"romane", "romanus", "romulus", "rubens", "ruber", "rubicon",
+		"rubicundus", "tesorería", "tesorero",
+	} {
+		r.Add(key, i)
+	}
+	r.Delete("rubicon")
+
+	data, err := r.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Expected to marshal the tree, got %s", err)
+	}
+
+	read := NewTree[int]()
+	read.Add("leftover", 100)
+	if err := read.UnmarshalBinary(data); err != nil {
+		t.Fatalf("Expected to unmarshal the tree, got %s", err)
+	}
+
+	if read.String() != r.String() {
+		t.Errorf("Expected the same structure:\n%s\ngot:\n%s",
+			r.String(), read.String())
+	}
+
+	keys, content := r.PrefixSearch("")
+	readKeys, readContent := read.PrefixSearch("")
+	if !slices.Equal(keys, readKeys) || !slices.Equal(content, readContent) {
+		t.Errorf("Expected %v %v, got %v %v", keys, content, readKeys, readContent)
+	}
+
+	if read.Len() != r.Len() || read.nodeCount != r.nodeCount {
+		t.Errorf("Expected %d keys and %d nodes, got %d and %d",
+			r.Len(), r.nodeCount, read.Len(), read.nodeCount)
+	}
+	if read.root.BitMask() != r.root.BitMask() {
+		t.Errorf("Expected the bit masks to be kept")
+	}
+	if key, _, _ := read.Select(3); key != "rubens" {
+		t.Errorf("Expected the counts to be rebuilt, got %s at 3", key)
+	}
+
+	// The tree read back is a normal tree which can be changed
+	read.Add("rubicon", 5)
+	if count := read.CountPrefix("rub"); count != 4 {
+		t.Errorf("Expected 4 keys under rub, got %d", count)
+	}
+}
+
+// Content held as interface{} needs its types registered with gob, or a
+// codec of its own
+func TestBinaryCodecs(t *testing.T) {
+
+	gob.Register(identifier{})
+
+	r := buildWikipediaExampleTree()
+
+	var buf bytes.Buffer
+	written, err := r.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("Expected to write the tree, got %s", err)
+	}
+	if written != int64(buf.Len()) {
+		t.Errorf("Expected %d bytes written, got %d", buf.Len(), written)
+	}
+
+	read := NewRadixTree()
+	readBytes, err := read.ReadFrom(&buf)
+	if err != nil {
+		t.Fatalf("Expected to read the tree, got %s", err)
+	}
+	if readBytes != written {
+		t.Errorf("Expected %d bytes read, got %d", written, readBytes)
+	}
+
+	keys, content := read.PrefixSearch("rub")
+	compareKeysAndContent(keys, content, t)
+	if len(keys) != 4 {
+		t.Errorf("Expected 4 keys under rub, got %v", keys)
+	}
+
+	j := NewTree[map[string]int]()
+	j.SetCodec(JSONCodec[map[string]int]{})
+	j.Add("kt1", map[string]int{"houses": 4})
+
+	data, err := j.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Expected to marshal with JSON, got %s", err)
+	}
+
+	readJSON := NewTree[map[string]int]()
+	readJSON.SetCodec(JSONCodec[map[string]int]{})
+	if err := readJSON.UnmarshalBinary(data); err != nil {
+		t.Fatalf("Expected to unmarshal with JSON, got %s", err)
+	}
+	if value, _ := readJSON.Get("kt1"); value["houses"] != 4 {
+		t.Errorf("Expected 4 houses, got %v", value)
+	}
+}
+
+// Damaged data should be rejected, leaving the tree as it was
+func TestBinaryErrors(t *testing.T) {
+
+	r := NewTree[string]()
+	r.Add("romane", "a")
+	r.Add("romanus", "b")
+
+	data, err := r.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Expected to marshal the tree, got %s", err)
+	}
+
+	corrupt := slices.Clone(data)
+	corrupt[len(corrupt)/2] ^= 0xff
+
+	checksum := slices.Clone(data)
+	checksum[len(checksum)-1] ^= 0xff
+
+	version := slices.Clone(data)
+	version[len(binaryMagic)] = binaryVersion + 1
+
+	testCases := []struct {
+		Data     []byte
+		Expected error
+	}{
+		{Data: []byte{}, Expected: ErrInvalidFormat},
+		{Data: []byte("not a tree"), Expected: ErrInvalidFormat},
+		{Data: version, Expected: ErrUnsupportedVersion},
+		{Data: checksum, Expected: ErrChecksumMismatch},
+		{Data: data[:len(data)-2], Expected: ErrInvalidFormat},
+		{Data: data[:len(data)-6], Expected: ErrInvalidFormat},
+		{Data: append(slices.Clone(data), 0), Expected: ErrInvalidFormat},
+	}
+
+	for _, test := range testCases {
+
+		read := NewTree[string]()
+		read.Add("leftover", "c")
+
+		err := read.UnmarshalBinary(test.Data)
+		if err != test.Expected {
+			t.Errorf("Expected %v for %q, got %v", test.Expected, test.Data, err)
+		}
+		if read.Len() != 1 || !read.Contains("leftover") {
+			t.Errorf("Expected the tree to be unchanged after an error")
+		}
+	}
+
+	if err := NewTree[string]().UnmarshalBinary(corrupt); err == nil {
+		t.Errorf("Expected corrupted data to be rejected")
+	}
+}
+
+// Reading a tree should take only its own bytes from the stream, leaving
+// whatever follows it, whether or not the reader can give single bytes
+func TestBinaryReadFromStream(t *testing.T) {
+
+	first := buildWikipediaExampleTree()
+	second := NewRadixTree()
+	second.Add("romane", "a")
+
+	var stream bytes.Buffer
+	firstBytes, _ := first.WriteTo(&stream)
+	secondBytes, _ := second.WriteTo(&stream)
+	stream.WriteString("trailer")
+	data := stream.Bytes()
+
+	for _, r := range []io.Reader{
+		bytes.NewReader(data),
+		struct{ io.Reader }{bytes.NewReader(data)},
+	} {
+
+		readFirst := NewRadixTree()
+		if n, err := readFirst.ReadFrom(r); err != nil || n != firstBytes {
+			t.Errorf("Expected to read %d bytes, got %d %v", firstBytes, n, err)
+		}
+		readSecond := NewRadixTree()
+		if n, err := readSecond.ReadFrom(r); err != nil || n != secondBytes {
+			t.Errorf("Expected to read %d bytes, got %d %v", secondBytes, n, err)
+		}
+
+		if readFirst.Len() != first.Len() || readSecond.Len() != 1 {
+			t.Errorf("Expected both trees to be read, got %d and %d keys",
+				readFirst.Len(), readSecond.Len())
+		}
+		if rest, _ := io.ReadAll(r); string(rest) != "trailer" {
+			t.Errorf("Expected the trailer to be left, got %q", rest)
+		}
+	}
+}
+
+// A corrupt length mustn't allocate a buffer of that length before the
+// data runs out
+func TestBinaryCorruptLength(t *testing.T) {
+
+	// A header and counts, then a root key claiming to be 512 MiB long
+	data := []byte(binaryMagic)
+	data = append(data, binaryVersion, byte(Mask32), 0, 0, 0)
+	data = binary.AppendUvarint(data, 1<<29)
+	data = append(data, "romane"...)
+
+	var before, after runtime.MemStats
+
+	runtime.ReadMemStats(&before)
+	err := NewTree[string]().UnmarshalBinary(data)
+	runtime.ReadMemStats(&after)
+
+	if err != ErrInvalidFormat {
+		t.Errorf("Expected the length to be rejected, got %v", err)
+	}
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 1<<20 {
+		t.Errorf("Expected little to be allocated, got %d bytes", allocated)
+	}
+
+	runtime.ReadMemStats(&before)
+	_, err = NewTree[string]().ReadFrom(bytes.NewReader(data))
+	runtime.ReadMemStats(&after)
+
+	if err != ErrInvalidFormat {
+		t.Errorf("Expected the stream to run out, got %v", err)
+	}
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 1<<20 {
+		t.Errorf("Expected little to be allocated, got %d bytes", allocated)
+	}
+}
+
+// The integration tree should survive the trip unchanged
+func TestBinaryIntegrationTree(t *testing.T) {
+
+	r := buildIntegrationTree()
+	r.SetCodec(emptyCodec{})
+	defer r.SetCodec(nil)
+
+	data, err := r.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Expected to marshal the tree, got %s", err)
+	}
+
+	read := NewRadixTree()
+	read.SetCodec(emptyCodec{})
+	if err := read.UnmarshalBinary(data); err != nil {
+		t.Fatalf("Expected to unmarshal the tree, got %s", err)
+	}
+
+	if read.Len() != r.Len() || read.nodeCount != r.nodeCount {
+		t.Errorf("Expected %d keys and %d nodes, got %d and %d",
+			r.Len(), r.nodeCount, read.Len(), read.nodeCount)
+	}
+
+	keys, _ := r.PrefixSearch("")
+	readKeys, _ := read.PrefixSearch("")
+	if !slices.Equal(keys, readKeys) {
+		t.Errorf("Expected the same keys")
+	}
+
+	keys, _ = r.FuzzySearch("smrst")
+	readKeys, _ = read.FuzzySearch("smrst")
+	if !slices.Equal(keys, readKeys) {
+		t.Errorf("Expected the same fuzzy search results")
+	}
+}
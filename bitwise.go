@@ -3,6 +3,13 @@
 // masks.
 package radix
 
+import (
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
 // Counts the number of bits that are set using voodoo black magic from
 // the gates of hell. Simplified as much as possible
 //
@@ -40,11 +47,22 @@ func numBitsSet64(n uint64) int {
 // Generates a bit mask based on the byte slice, compressing it to 32 bits.
 // Priority is given to letters, numbers are compress by half, special
 // characters are the final bit.
+//
+// The bytes are read as UTF-8, accented Latin letters set the bit of the
+// letter they're based on and letters from other scripts are spread across
+// the letter bits.
 func genBitMask(str []byte) uint32 {
 
 	mask := uint32(0)
 
-	for _, r := range str {
+	for len(str) > 0 {
+
+		r, size := utf8.DecodeRune(str)
+		str = str[size:]
+
+		if base, ok := latinBase(r); ok {
+			r = base
+		}
 
 		setBit := uint32(0)
 
@@ -61,6 +79,11 @@ func genBitMask(str []byte) uint32 {
 			number := uint32(r - 48)
 			setBit = (number / 2) + 26
 
+		} else if r >= utf8.RuneSelf && unicode.IsLetter(r) {
+			// Non-Latin letters share the letter bits, a false positive
+			// only costs a wasted descent
+			setBit = uint32(unicode.ToLower(r) % 26)
+
 		} else {
 			// All other characters (special characters etc.) will appear as character
 			// 32
@@ -75,7 +98,82 @@ func genBitMask(str []byte) uint32 {
 	return mask
 }
 
+// Generates the bit mask of a run of bytes, each tree has one depending on
+// its mask mode
+type bitMasker func(str []byte) uint64
+
+// The 32 bit mask, as used in Mask32 mode
+func genBitMask32(str []byte) uint64 {
+	return uint64(genBitMask(str))
+}
+
+// The punctuation which is given a bit of its own in the 64 bit mask, from
+// bit 36 onwards
+const maskPunctuation = " ,.-'/&()#:;"
+
+// The accented Latin-1 letters which are given a bit of their own in the 64
+// bit mask, from bit 48 onwards. These are the most frequent in Western
+// European place names, other accented letters share the bit of their base
+var maskLatin1 = []rune("áàâäéèêíñóôöúü")
+
+// Generates a bit mask based on the byte slice using all 64 bits. Letters
+// have the same bits as in the 32 bit mask, but each digit, the common
+// punctuation and the common accented letters get a bit of their own:
+//
+//   - 0-25 are a-z, along with other accented letters and non-Latin letters
+//   - 26-35 are the digits 0-9
+//   - 36-47 are the punctuation in maskPunctuation
+//   - 48-61 are the accented letters in maskLatin1
+//   - 62 is any other punctuation or symbol, 63 is everything else
+func genBitMask64(str []byte) uint64 {
+
+	mask := uint64(0)
+
+	for len(str) > 0 {
+
+		r, size := utf8.DecodeRune(str)
+		str = str[size:]
+
+		mask |= 1 << bit64(r)
+	}
+
+	return mask
+}
+
+// Returns the bit a single letter sets in the 64 bit mask
+func bit64(r rune) uint {
+
+	switch {
+	case r >= 'a' && r <= 'z':
+		return uint(r - 'a')
+	case r >= 'A' && r <= 'Z':
+		return uint(r - 'A')
+	case r >= '0' && r <= '9':
+		return uint(r-'0') + 26
+	case r < utf8.RuneSelf:
+		if i := strings.IndexRune(maskPunctuation, r); i >= 0 {
+			return uint(i) + 36
+		}
+	default:
+		if i := slices.Index(maskLatin1, unicode.ToLower(r)); i >= 0 {
+			return uint(i) + 48
+		}
+		if base, ok := latinBase(r); ok {
+			return uint(base - 'a')
+		}
+		if unicode.IsLetter(r) {
+			return uint(unicode.ToLower(r) % 26)
+		}
+	}
+
+	if unicode.IsPunct(r) || unicode.IsSymbol(r) {
+		return 62
+	}
+
+	return 63
+}
+
 // Checks if bitmask needle contains the bits in haystack
-func bitMaskContains(haystack, needle uint32) bool {
+func bitMaskContains[T uint32 | uint64](haystack, needle T) bool {
 	return (haystack & needle) == needle
 }
@@ -179,3 +179,84 @@ func TestBitMaskContains(t *testing.T) {
 		}
 	}
 }
+
+// Accented letters should share the bit of their plain letter, and other
+// scripts should not all land on the special character bit
+func TestGenBitMaskUnicode(t *testing.T) {
+
+	testCases := []struct {
+		Input    string
+		Expected string
+	}{
+		{Input: "é", Expected: "e"},
+		{Input: "alcalá de henares", Expected: "alcala de henares"},
+		{Input: "ÑŽ", Expected: "nz"},
+	}
+
+	for _, test := range testCases {
+		res := genBitMask([]byte(test.Input))
+		expected := genBitMask([]byte(test.Expected))
+		if res != expected {
+			t.Errorf("Expected bit mask of %s to match %s, got %b and %b",
+				test.Input, test.Expected, res, expected)
+		}
+	}
+
+	if genBitMask([]byte("α")) == genBitMask([]byte("β")) {
+		t.Errorf("Expected different Greek letters to set different bits")
+	}
+}
+
+// Each digit, the common punctuation and the common accented letters should
+// have a bit of their own in the 64 bit mask, and letters should keep the
+// bits they have in the 32 bit mask
+func TestGenBitMask64(t *testing.T) {
+
+	distinct := "abcdefghijklmnopqrstuvwxyz0123456789" + maskPunctuation +
+		string(maskLatin1)
+
+	seen := make(map[uint64]rune)
+	for _, r := range distinct {
+
+		mask := genBitMask64([]byte(string(r)))
+		if numBitsSet64(mask) != 1 {
+			t.Errorf("Expected %q to set a single bit, got %b", r, mask)
+		}
+		if other, ok := seen[mask]; ok {
+			t.Errorf("Expected %q and %q to set different bits", r, other)
+		}
+		seen[mask] = r
+	}
+
+	for r := 'a'; r <= 'z'; r++ {
+		if genBitMask64([]byte{byte(r)}) != genBitMask32([]byte{byte(r)}) {
+			t.Errorf("Expected %q to set the same bit in both masks", r)
+		}
+	}
+
+	testCases := []struct {
+		Input    string
+		Expected string
+	}{
+		{Input: "É", Expected: "é"},
+		{Input: "ç", Expected: "c"},
+		{Input: "ROMA 12", Expected: "roma 21"},
+		{Input: "!", Expected: "€"},
+	}
+
+	for _, test := range testCases {
+		res := genBitMask64([]byte(test.Input))
+		expected := genBitMask64([]byte(test.Expected))
+		if res != expected {
+			t.Errorf("Expected bit mask of %s to match %s, got %b and %b",
+				test.Input, test.Expected, res, expected)
+		}
+	}
+
+	if genBitMask64([]byte("é")) == genBitMask64([]byte("e")) {
+		t.Errorf("Expected é to set a different bit to e")
+	}
+	if genBitMask64([]byte("€")) == genBitMask64([]byte("\t")) {
+		t.Errorf("Expected symbols to set a different bit to other letters")
+	}
+}
@@ -0,0 +1,192 @@
+package radix
+
+import (
+	"bytes"
+	"errors"
+	"iter"
+	"slices"
+)
+
+// ErrUnsorted is returned by BuildFromSorted when a key comes before the one
+// given ahead of it
+var ErrUnsorted = errors.New("Keys are not in sorted order")
+
+// A node which is still being built, along with where its key starts and
+// ends within the full keys passing through it
+type openNode[V any] struct {
+	node  *treeNode[V]
+	start int
+	end   int
+}
+
+// BuildFromSorted builds a tree in one pass from keys given in lexicographic
+// (byte) order, which is much faster than adding them one at a time. The
+// options are those of NewTree, if they normalise the keys it is the
+// normalised keys which must be in order. Nodes are built bottom up, each
+// one is finished with (its bit mask and count set once) as soon as a key
+// arrives which can't fall below it. If a key is repeated the last content
+// wins, as with Add.
+//
+// ErrUnsorted is returned if the keys are out of order
+func BuildFromSorted[V any](
+	seq iter.Seq2[string, V],
+	opts ...Option,
+) (*Tree[V], error) {
+
+	tree := NewTree[V](opts...)
+
+	// The nodes along the path of the previous key, the root first
+	stack := []openNode[V]{{node: tree.root}}
+	var previous []byte
+
+	for str, content := range seq {
+
+		key := tree.stringToBytes(str)
+		if len(key) == 0 {
+			continue
+		}
+
+		cmp := 0
+		if previous != nil {
+			cmp = bytes.Compare(key, previous)
+		}
+
+		if cmp < 0 {
+			return nil, ErrUnsorted
+		}
+
+		// The same key again, so it's the last node on the path
+		if previous != nil && cmp == 0 {
+			stack[len(stack)-1].node.SetContent(content)
+			stack[len(stack)-1].node.setOriginal(str, key)
+			continue
+		}
+
+		// How much of the path the key shares, never part way through a
+		// letter
+		common := 0
+		for common < len(key) &&
+			common < len(previous) &&
+			key[common] == previous[common] {
+			common++
+		}
+		common = runeStartIndex(key, common)
+
+		// Everything on the path past the shared part is complete
+		for stack[len(stack)-1].end > common {
+
+			finished := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			parent := stack[len(stack)-1]
+
+			// The key branches part way through the node, so it is split
+			// with a new node for the shared part
+			if parent.end < common {
+
+				shared := &treeNode[V]{
+					key:        finished.node.key[:common-finished.start],
+					children:   []*treeNode[V]{finished.node},
+					generation: tree.generation,
+				}
+
+				finished.node.key = finished.node.key[common-finished.start:]
+				parent.node.children[len(parent.node.children)-1] = shared
+				tree.nodeCount++
+
+				stack = append(stack, openNode[V]{
+					node:  shared,
+					start: finished.start,
+					end:   common,
+				})
+			}
+
+			tree.finish(finished.node)
+		}
+
+		leaf := &treeNode[V]{
+			key:        key[common:],
+			content:    content,
+			doCollect:  true,
+			generation: tree.generation,
+		}
+		leaf.setOriginal(str, key)
+
+		top := stack[len(stack)-1].node
+		top.children = append(top.children, leaf)
+		tree.nodeCount++
+		tree.stringCount++
+
+		stack = append(stack, openNode[V]{
+			node:  leaf,
+			start: common,
+			end:   len(key),
+		})
+
+		previous = key
+	}
+
+	for i := len(stack) - 1; i > 0; i-- {
+		tree.finish(stack[i].node)
+	}
+
+	// The root's bit mask is never checked, as with Add it is left empty
+	tree.root.size = tree.stringCount
+
+	return tree, nil
+}
+
+// BuildFromUnsorted builds a tree in one pass from keys in any order, they
+// are sorted first. If a key is repeated the last content wins, as with Add
+func BuildFromUnsorted[V any](
+	seq iter.Seq2[string, V],
+	opts ...Option,
+) *Tree[V] {
+
+	type entry struct {
+		key        string
+		normalised []byte
+		content    V
+	}
+
+	options := newOptions(opts...)
+
+	entries := []entry{}
+	for key, content := range seq {
+		normalised := options.normalisation.normalise(key)
+		entries = append(entries, entry{key, normalised, content})
+	}
+
+	// Stable so that repeated keys stay in the order given
+	slices.SortStableFunc(entries, func(a, b entry) int {
+		return bytes.Compare(a.normalised, b.normalised)
+	})
+
+	tree, _ := BuildFromSorted(func(yield func(string, V) bool) {
+		for _, e := range entries {
+			if !yield(e.key, e.content) {
+				return
+			}
+		}
+	}, opts...)
+
+	return tree
+}
+
+// Sets the bit mask, signature and count of a node once everything below it
+// is built
+func (tree *Tree[V]) finish(node *treeNode[V]) {
+
+	node.RebuildBitMask(tree.masker())
+	if tree.options.bigrams {
+		node.RebuildBigrams(tree.masker())
+	}
+
+	node.size = 0
+	if node.Collect() {
+		node.size = 1
+	}
+
+	for _, child := range node.Children() {
+		node.size += child.Size()
+	}
+}
@@ -0,0 +1,130 @@
+package radix
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// Makes a sequence from parallel slices of keys and content
+func pairs[V any](keys []string, content []V) func(func(string, V) bool) {
+	return func(yield func(string, V) bool) {
+		for i := range keys {
+			if !yield(keys[i], content[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Checks that two trees are built from the same nodes, with the same keys,
+// bit masks, counts, content and keys as inserted
+func compareNodes[V comparable](
+	expected *treeNode[V],
+	actual *treeNode[V],
+	path string,
+	t *testing.T,
+) {
+
+	t.Helper()
+
+	path += string(expected.Key())
+	if string(actual.Key()) != string(expected.Key()) ||
+		actual.BitMask() != expected.BitMask() ||
+		actual.Collect() != expected.Collect() ||
+		actual.Size() != expected.Size() ||
+		actual.Content() != expected.Content() ||
+		actual.original != expected.original ||
+		len(actual.Children()) != len(expected.Children()) {
+
+		t.Fatalf("Expected node %q to be %q %b %t %d %v with %d children, "+
+			"got %q %b %t %d %v with %d children",
+			path,
+			expected.Key(), expected.BitMask(), expected.Collect(),
+			expected.Size(), expected.Content(), len(expected.Children()),
+			actual.Key(), actual.BitMask(), actual.Collect(),
+			actual.Size(), actual.Content(), len(actual.Children()))
+	}
+
+	for i := range expected.Children() {
+		compareNodes(expected.Children()[i], actual.Children()[i], path, t)
+	}
+}
+
+// A tree built from sorted keys should be the same as one built by adding
+// them one at a time
+func TestBuildFromSorted(t *testing.T) {
+
+	keys := []string{
+		"café", "cafés", "cafí", "rom", "roma", "romane", "romane",
+		"romanus", "romulus", "rubens", "ruber", "rubicon", "rubicundus",
+		"tesorero", "tesorería",
+	}
+	content := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+	expected := NewTree[int]()
+	for i, key := range keys {
+		expected.Add(key, content[i])
+	}
+
+	built, err := BuildFromSorted(pairs(keys, content))
+	if err != nil {
+		t.Fatalf("Expected to build the tree, got %s", err)
+	}
+
+	compareNodes(expected.root, built.root, "", t)
+
+	if built.Len() != expected.Len() || built.nodeCount != expected.nodeCount {
+		t.Errorf("Expected %d keys and %d nodes, got %d and %d",
+			expected.Len(), expected.nodeCount, built.Len(), built.nodeCount)
+	}
+
+	// It's a normal tree afterwards
+	built.Add("romanes", 16)
+	built.Delete("ruber")
+	expected.Add("romanes", 16)
+	expected.Delete("ruber")
+	compareNodes(expected.root, built.root, "", t)
+
+	if _, err := BuildFromSorted(pairs([]string{"b", "a"}, []int{1, 2})); err != ErrUnsorted {
+		t.Errorf("Expected unsorted keys to fail, got %v", err)
+	}
+
+	empty, err := BuildFromSorted(pairs([]string{}, []int{}))
+	if err != nil || empty.Len() != 0 {
+		t.Errorf("Expected an empty tree from no keys")
+	}
+}
+
+// Keys in any order are sorted first, with the last of any repeated key
+// winning
+func TestBuildFromUnsorted(t *testing.T) {
+
+	keys := []string{"romulus", "romane", "rubens", "romane", "ruber"}
+	content := []int{1, 2, 3, 4, 5}
+
+	built := BuildFromUnsorted(pairs(keys, content))
+
+	builtKeys, builtContent := built.PrefixSearch("")
+	if !slices.Equal(builtKeys, []string{"romane", "romulus", "rubens", "ruber"}) {
+		t.Errorf("Expected the keys in order, got %v", builtKeys)
+	}
+	if !slices.Equal(builtContent, []int{4, 1, 3, 5}) {
+		t.Errorf("Expected the last content to win, got %v", builtContent)
+	}
+}
+
+// The integration tree built in one pass should match the one built by
+// adding each address
+func TestBuildIntegrationTree(t *testing.T) {
+
+	expected := buildIntegrationTree()
+	keys, content := expected.PrefixSearch("")
+
+	rand.New(rand.NewSource(1)).Shuffle(len(keys), func(i, j int) {
+		keys[i], keys[j] = keys[j], keys[i]
+	})
+
+	built := BuildFromUnsorted(pairs(keys, content))
+	compareNodes(expected.root, built.root, "", t)
+}
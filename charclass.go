@@ -0,0 +1,160 @@
+package radix
+
+import (
+	"cmp"
+	"slices"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CharClassMapper decides which bit of a node's bit mask each letter sets,
+// from 0 to 63. Letters sharing a bit can't be told apart by the fuzzy
+// search, so a good mapper spreads the letters common in the keys across
+// as many bits as it can. The mapper must give the same bit for the same
+// letter every time it is called
+type CharClassMapper func(r rune) uint
+
+// Trees made with a CharClassMapper are saved with this mode, as there is no
+// way of saving the mapper itself
+const maskCustom MaskMode = 255
+
+// WithCharClassMapper sets the mapper the bit masks of the tree are
+// generated with, in place of the mask mode
+func WithCharClassMapper(mapper CharClassMapper) Option {
+	return func(options *treeOptions) {
+		options.mapper = mapper
+	}
+}
+
+// Generates a bit mask based on the byte slice, with each letter setting the
+// bit the mapper gives it
+func (mapper CharClassMapper) genBitMask(str []byte) uint64 {
+
+	mask := uint64(0)
+
+	for len(str) > 0 {
+
+		r, size := utf8.DecodeRune(str)
+		str = str[size:]
+
+		mask |= 1 << (mapper(r) & 63)
+	}
+
+	return mask
+}
+
+// Used when the masks were made by a mapper which isn't known. Nothing is
+// needed of a node, so the fuzzy search never skips one
+func unknownBitMask([]byte) uint64 {
+	return 0
+}
+
+// EnglishMapper gives each of the letters a-z a bit, ignoring case and
+// accents, and each digit a bit after them. Other punctuation and symbols
+// share bit 62 and everything else shares bit 63
+func EnglishMapper(r rune) uint {
+
+	if base, ok := latinBase(r); ok {
+		r = base
+	}
+
+	switch {
+	case r >= 'a' && r <= 'z':
+		return uint(r - 'a')
+	case r >= 'A' && r <= 'Z':
+		return uint(r - 'A')
+	case r >= '0' && r <= '9':
+		return uint(r-'0') + 26
+	case unicode.IsPunct(r) || unicode.IsSymbol(r):
+		return 62
+	}
+
+	return 63
+}
+
+// HexMapper gives each hex digit a bit of its own, ignoring case, for keys
+// such as part numbers and hashes. Everything else shares bit 63
+func HexMapper(r rune) uint {
+
+	switch {
+	case r >= '0' && r <= '9':
+		return uint(r - '0')
+	case r >= 'a' && r <= 'f':
+		return uint(r-'a') + 10
+	case r >= 'A' && r <= 'F':
+		return uint(r-'A') + 10
+	}
+
+	return 63
+}
+
+// DNAMapper gives each of the bases A, C, G and T a bit of its own, ignoring
+// case, with U sharing the bit of T and the unknown base N a bit after them.
+// Everything else shares bit 63
+func DNAMapper(r rune) uint {
+
+	switch unicode.ToUpper(r) {
+	case 'A':
+		return 0
+	case 'C':
+		return 1
+	case 'G':
+		return 2
+	case 'T', 'U':
+		return 3
+	case 'N':
+		return 4
+	}
+
+	return 63
+}
+
+// FrequencyMapper learns a mapper from a sample of the keys. The 63 most
+// frequent letters (ignoring case) are given a bit each, and the rest are
+// shared out between the bits so that each is set about as often. Letters
+// not seen in the sample share the last bit
+func FrequencyMapper(sample []string) CharClassMapper {
+
+	counts := make(map[rune]int)
+	for _, key := range sample {
+		for _, r := range key {
+			counts[unicode.ToLower(r)]++
+		}
+	}
+
+	letters := make([]rune, 0, len(counts))
+	for r := range counts {
+		letters = append(letters, r)
+	}
+
+	// Most frequent first, ties in letter order so the mapper is the same
+	// for the same sample
+	slices.SortFunc(letters, func(a, b rune) int {
+		if c := cmp.Compare(counts[b], counts[a]); c != 0 {
+			return c
+		}
+		return cmp.Compare(a, b)
+	})
+
+	bits := make(map[rune]uint, len(letters))
+	var load [63]int
+
+	for i, r := range letters {
+
+		bit := uint(i)
+		if i >= len(load) {
+			// The least used bit takes the next letter
+			bit = uint(slices.Index(load[:], slices.Min(load[:])))
+		}
+
+		bits[r] = bit
+		load[bit] += counts[r]
+	}
+
+	return func(r rune) uint {
+		if bit, ok := bits[unicode.ToLower(r)]; ok {
+			return bit
+		}
+		return 63
+	}
+}
@@ -0,0 +1,195 @@
+package radix
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// Checks that each letter sets a bit no other letter sets
+func checkDistinctBits(mapper CharClassMapper, letters string, t *testing.T) {
+
+	seen := make(map[uint]rune)
+	for _, r := range letters {
+
+		bit := mapper(r)
+		if other, ok := seen[bit]; ok {
+			t.Errorf("Expected %q and %q to set different bits, both set %d",
+				r, other, bit)
+		}
+		seen[bit] = r
+	}
+}
+
+func TestBuiltInMappers(t *testing.T) {
+
+	checkDistinctBits(EnglishMapper, "abcdefghijklmnopqrstuvwxyz0123456789,", t)
+	checkDistinctBits(HexMapper, "0123456789abcdefx", t)
+	checkDistinctBits(DNAMapper, "ACGTNX", t)
+
+	testCases := []struct {
+		Mapper   CharClassMapper
+		Input    string
+		Expected string
+	}{
+		{Mapper: EnglishMapper, Input: "Alarcón", Expected: "alarcon"},
+		{Mapper: EnglishMapper, Input: "!", Expected: "€"},
+		{Mapper: HexMapper, Input: "DEADBEEF", Expected: "deadbeef"},
+		{Mapper: HexMapper, Input: "xyz-", Expected: "g"},
+		{Mapper: DNAMapper, Input: "acgu", Expected: "ACGT"},
+	}
+
+	for _, test := range testCases {
+		res := test.Mapper.genBitMask([]byte(test.Input))
+		expected := test.Mapper.genBitMask([]byte(test.Expected))
+		if res != expected {
+			t.Errorf("Expected bit mask of %s to match %s, got %b and %b",
+				test.Input, test.Expected, res, expected)
+		}
+	}
+}
+
+func TestFrequencyMapper(t *testing.T) {
+
+	mapper := FrequencyMapper([]string{
+		"αλφα", "βήτα", "γάμμα", "δέλτα", "ΨΩ",
+	})
+
+	checkDistinctBits(mapper, "αλφβήτγάμδέψω", t)
+
+	if mapper('Ψ') != mapper('ψ') {
+		t.Errorf("Expected the mapper to ignore case")
+	}
+	if mapper('z') != 63 {
+		t.Errorf("Expected letters not in the sample to set bit 63, got %d",
+			mapper('z'))
+	}
+
+	// The most frequent letter comes first
+	if mapper('α') != 0 {
+		t.Errorf("Expected α to set bit 0, got %d", mapper('α'))
+	}
+
+	// With more letters than bits, every bit should be used and letters
+	// beyond the first 63 share them
+	var sample []string
+	for r := rune(0x4e00); r < 0x4e00+200; r++ {
+		sample = append(sample, string(r))
+	}
+	mapper = FrequencyMapper(sample)
+
+	used := make(map[uint]int)
+	for _, key := range sample {
+		used[mapper([]rune(key)[0])]++
+	}
+	if len(used) != 63 {
+		t.Errorf("Expected 63 bits to be used, got %d", len(used))
+	}
+	for bit, count := range used {
+		if count < 3 || count > 4 {
+			t.Errorf("Expected bit %d to be shared evenly, got %d letters",
+				bit, count)
+		}
+	}
+}
+
+// Keys using a small alphabet should find the same keys with a mapper made
+// for them, while ruling out more of the tree
+func TestCharClassMapperTree(t *testing.T) {
+
+	var keys []string
+	for i := 0; i < 2000; i++ {
+		keys = append(keys, fmt.Sprintf("%08x", uint32(i)*2654435761))
+	}
+
+	standard := NewTree[int]()
+	hex := NewTree[int](WithCharClassMapper(HexMapper))
+	for i, key := range keys {
+		standard.Add(key, i)
+		hex.Add(key, i)
+	}
+
+	for _, search := range []string{"9e", "cafe", "0d1", "b0b"} {
+
+		standardKeys, _ := standard.FuzzySearch(search)
+		hexKeys, _ := hex.FuzzySearch(search)
+		if !slices.Equal(standardKeys, hexKeys) {
+			t.Errorf("Expected %s to find the same keys with the mapper, got %v and %v",
+				search, standardKeys, hexKeys)
+		}
+
+		if countMaskMatches(hex, search) > countMaskMatches(standard, search) {
+			t.Errorf("Expected %s to match fewer nodes with the mapper", search)
+		}
+	}
+
+	if countMaskMatches(hex, "0d1") >= countMaskMatches(standard, "0d1") {
+		t.Errorf("Expected 0d1 to match fewer nodes with the mapper, got %d and %d",
+			countMaskMatches(hex, "0d1"), countMaskMatches(standard, "0d1"))
+	}
+}
+
+// Masks made by a mapper should always be rebuilt when read, and a mapped
+// tree should use the mapper it is opened with
+func TestCharClassMapperSaving(t *testing.T) {
+
+	keys := []string{"ACGTAC", "ACGTTA", "AGGA", "CCNA", "TTGA"}
+
+	r := NewTree[int](WithCharClassMapper(DNAMapper))
+	for i, key := range keys {
+		r.Add(key, i)
+	}
+
+	var buf bytes.Buffer
+	if _, err := r.WriteTo(&buf); err != nil {
+		t.Fatalf("Expected to write the tree, got %s", err)
+	}
+
+	for _, opts := range [][]Option{
+		{},
+		{WithMaskMode(Mask64)},
+		{WithCharClassMapper(DNAMapper)},
+		{WithCharClassMapper(EnglishMapper)},
+	} {
+
+		loaded := NewTree[int](opts...)
+		if _, err := loaded.ReadFrom(bytes.NewReader(buf.Bytes())); err != nil {
+			t.Fatalf("Expected to read the tree, got %s", err)
+		}
+
+		rebuilt := NewTree[int](opts...)
+		for i, key := range keys {
+			rebuilt.Add(key, i)
+		}
+		compareNodes(rebuilt.root, loaded.root, "", t)
+	}
+
+	for _, opts := range [][]Option{{}, {WithCharClassMapper(DNAMapper)}} {
+
+		path := filepath.Join(t.TempDir(), "tree.mapped")
+		var mapped bytes.Buffer
+		if _, err := r.WriteMapped(&mapped); err != nil {
+			t.Fatalf("Expected to write the tree, got %s", err)
+		}
+		if err := os.WriteFile(path, mapped.Bytes(), 0o644); err != nil {
+			t.Fatalf("Expected to save the tree, got %s", err)
+		}
+
+		mt, err := OpenMapped[int](path, opts...)
+		if err != nil {
+			t.Fatalf("Expected to open the tree, got %s", err)
+		}
+
+		for _, search := range []string{"AC", "GA", "N", "CCC"} {
+			expected, _ := r.FuzzySearch(search)
+			found, _ := mt.FuzzySearch(search)
+			if !slices.Equal(expected, found) {
+				t.Errorf("Expected %s to find %v, got %v", search, expected, found)
+			}
+		}
+		mt.Close()
+	}
+}
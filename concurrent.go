@@ -0,0 +1,352 @@
+package radix
+
+import (
+	"iter"
+	"sync"
+)
+
+// ConcurrentTree guards a Tree with a read/write lock so that it can be
+// searched from many goroutines while others are adding to it. Searches run
+// alongside each other, changes wait for the searches to finish.
+//
+// Functions passed to the walks are called with the read lock held, so they
+// must not call any method of the tree. Not even a search, as once a change
+// is waiting for the lock no new reader is let in. The iterators walk a
+// snapshot instead, so the loops over them are free to use the tree
+type ConcurrentTree[V any] struct {
+	lock sync.RWMutex
+	tree *Tree[V]
+}
+
+// ConcurrentRadixTree is a ConcurrentTree which can hold content of any type
+type ConcurrentRadixTree = ConcurrentTree[interface{}]
+
+// NewConcurrentTree sets up and returns a ConcurrentTree struct for content
+// of type V
+func NewConcurrentTree[V any](opts ...Option) *ConcurrentTree[V] {
+	return &ConcurrentTree[V]{
+		tree: NewTree[V](opts...),
+	}
+}
+
+// NewConcurrentRadixTree sets up and returns a ConcurrentRadixTree struct
+func NewConcurrentRadixTree(opts ...Option) *ConcurrentRadixTree {
+	return NewConcurrentTree[interface{}](opts...)
+}
+
+// Add inserts a key, replacing the content if it exists. Returns whether the
+// key was new
+func (ct *ConcurrentTree[V]) Add(str string, content V) bool {
+
+	ct.lock.Lock()
+	defer ct.lock.Unlock()
+
+	_, isNew := ct.tree.Add(str, content)
+	return isNew
+}
+
+// AddIfAbsent inserts the key only if it doesn't already exist
+func (ct *ConcurrentTree[V]) AddIfAbsent(str string, content V) bool {
+
+	ct.lock.Lock()
+	defer ct.lock.Unlock()
+
+	return ct.tree.AddIfAbsent(str, content)
+}
+
+// Replace sets the content of a key only if it already exists
+func (ct *ConcurrentTree[V]) Replace(str string, content V) bool {
+
+	ct.lock.Lock()
+	defer ct.lock.Unlock()
+
+	return ct.tree.Replace(str, content)
+}
+
+// Update sets the content of a key based on what is currently stored, the
+// function is called with the write lock held
+func (ct *ConcurrentTree[V]) Update(
+	str string,
+	fn func(content V, exists bool) V,
+) {
+
+	ct.lock.Lock()
+	defer ct.lock.Unlock()
+
+	ct.tree.Update(str, fn)
+}
+
+// Delete removes a single key
+func (ct *ConcurrentTree[V]) Delete(str string) bool {
+
+	ct.lock.Lock()
+	defer ct.lock.Unlock()
+
+	return ct.tree.Delete(str)
+}
+
+// DeletePrefix removes every key beginning with the prefix
+func (ct *ConcurrentTree[V]) DeletePrefix(str string) int {
+
+	ct.lock.Lock()
+	defer ct.lock.Unlock()
+
+	return ct.tree.DeletePrefix(str)
+}
+
+// Get returns the content stored against an exact key
+func (ct *ConcurrentTree[V]) Get(str string) (V, bool) {
+
+	ct.lock.RLock()
+	defer ct.lock.RUnlock()
+
+	return ct.tree.Get(str)
+}
+
+// Contains checks whether the exact key has been added to the tree
+func (ct *ConcurrentTree[V]) Contains(str string) bool {
+
+	ct.lock.RLock()
+	defer ct.lock.RUnlock()
+
+	return ct.tree.Contains(str)
+}
+
+// Len returns the number of distinct keys held in the tree
+func (ct *ConcurrentTree[V]) Len() int {
+
+	ct.lock.RLock()
+	defer ct.lock.RUnlock()
+
+	return ct.tree.Len()
+}
+
+// CountPrefix returns the number of keys beginning with the prefix
+func (ct *ConcurrentTree[V]) CountPrefix(str string) int {
+
+	ct.lock.RLock()
+	defer ct.lock.RUnlock()
+
+	return ct.tree.CountPrefix(str)
+}
+
+// LongestPrefix returns the longest key that is a prefix of the string
+func (ct *ConcurrentTree[V]) LongestPrefix(str string) (string, bool) {
+
+	ct.lock.RLock()
+	defer ct.lock.RUnlock()
+
+	return ct.tree.LongestPrefix(str)
+}
+
+// PrefixSearch returns every key beginning with the prefix
+func (ct *ConcurrentTree[V]) PrefixSearch(str string) ([]string, []V) {
+	return ct.PrefixSearchWithOptions(str, SearchOptions{})
+}
+
+// PrefixSearchWithOptions performs a prefix search bounded by the options
+func (ct *ConcurrentTree[V]) PrefixSearchWithOptions(
+	str string,
+	opts SearchOptions,
+) ([]string, []V) {
+
+	ct.lock.RLock()
+	defer ct.lock.RUnlock()
+
+	return ct.tree.PrefixSearchWithOptions(str, opts)
+}
+
+// FuzzySearch returns every key matching the fuzzy search
+func (ct *ConcurrentTree[V]) FuzzySearch(str string) ([]string, []V) {
+	return ct.FuzzySearchWithOptions(str, SearchOptions{})
+}
+
+// FuzzySearchWithOptions performs a fuzzy search bounded by the options
+func (ct *ConcurrentTree[V]) FuzzySearchWithOptions(
+	str string,
+	opts SearchOptions,
+) ([]string, []V) {
+
+	ct.lock.RLock()
+	defer ct.lock.RUnlock()
+
+	return ct.tree.FuzzySearchWithOptions(str, opts)
+}
+
+// FuzzySearchRanked returns the fuzzy search results best first
+func (ct *ConcurrentTree[V]) FuzzySearchRanked(
+	str string,
+	opts SearchOptions,
+) []FuzzyResult[V] {
+
+	ct.lock.RLock()
+	defer ct.lock.RUnlock()
+
+	return ct.tree.FuzzySearchRanked(str, opts)
+}
+
+// SearchWithinDistance finds every key within maxEdits of the string
+func (ct *ConcurrentTree[V]) SearchWithinDistance(
+	str string,
+	maxEdits int,
+) []DistanceResult[V] {
+
+	ct.lock.RLock()
+	defer ct.lock.RUnlock()
+
+	return ct.tree.SearchWithinDistance(str, maxEdits)
+}
+
+// WalkPrefix calls the function for every key beginning with the prefix
+func (ct *ConcurrentTree[V]) WalkPrefix(
+	str string,
+	fn func(key string, value V) bool,
+) {
+
+	ct.lock.RLock()
+	defer ct.lock.RUnlock()
+
+	ct.tree.WalkPrefix(str, fn)
+}
+
+// WalkFuzzy calls the function for every key matching the fuzzy search
+func (ct *ConcurrentTree[V]) WalkFuzzy(
+	str string,
+	fn func(key string, value V) bool,
+) {
+
+	ct.lock.RLock()
+	defer ct.lock.RUnlock()
+
+	ct.tree.WalkFuzzy(str, fn)
+}
+
+// Range calls the function for every key between from and to
+func (ct *ConcurrentTree[V]) Range(
+	from string,
+	to string,
+	fromInclusive bool,
+	toInclusive bool,
+	fn func(key string, value V) bool,
+) {
+
+	ct.lock.RLock()
+	defer ct.lock.RUnlock()
+
+	ct.tree.Range(from, to, fromInclusive, toInclusive, fn)
+}
+
+// All returns an iterator over every key, as they were when the iteration
+// started
+func (ct *ConcurrentTree[V]) All() iter.Seq2[string, V] {
+	return ct.Prefix("")
+}
+
+// Prefix returns an iterator over every key beginning with the prefix, as
+// they were when the iteration started
+func (ct *ConcurrentTree[V]) Prefix(str string) iter.Seq2[string, V] {
+	return func(yield func(string, V) bool) {
+		ct.snapshot().WalkPrefix(str, yield)
+	}
+}
+
+// Fuzzy returns an iterator over every key matching the fuzzy search, as
+// they were when the iteration started
+func (ct *ConcurrentTree[V]) Fuzzy(str string) iter.Seq2[string, V] {
+	return func(yield func(string, V) bool) {
+		ct.snapshot().WalkFuzzy(str, yield)
+	}
+}
+
+// Returns a snapshot of the tree to iterate over, so that no lock is held
+// while the loop runs. Taking it changes the generation of the tree, so it
+// needs the write lock
+func (ct *ConcurrentTree[V]) snapshot() *Tree[V] {
+
+	ct.lock.Lock()
+	defer ct.lock.Unlock()
+
+	return ct.tree.Snapshot()
+}
+
+// Min returns the smallest key in the tree
+func (ct *ConcurrentTree[V]) Min() (string, V, bool) {
+
+	ct.lock.RLock()
+	defer ct.lock.RUnlock()
+
+	return ct.tree.Min()
+}
+
+// Max returns the largest key in the tree
+func (ct *ConcurrentTree[V]) Max() (string, V, bool) {
+
+	ct.lock.RLock()
+	defer ct.lock.RUnlock()
+
+	return ct.tree.Max()
+}
+
+// Floor returns the largest key less than or equal to the string
+func (ct *ConcurrentTree[V]) Floor(str string) (string, V, bool) {
+
+	ct.lock.RLock()
+	defer ct.lock.RUnlock()
+
+	return ct.tree.Floor(str)
+}
+
+// Ceiling returns the smallest key greater than or equal to the string
+func (ct *ConcurrentTree[V]) Ceiling(str string) (string, V, bool) {
+
+	ct.lock.RLock()
+	defer ct.lock.RUnlock()
+
+	return ct.tree.Ceiling(str)
+}
+
+// Next returns the smallest key strictly greater than the string
+func (ct *ConcurrentTree[V]) Next(str string) (string, V, bool) {
+
+	ct.lock.RLock()
+	defer ct.lock.RUnlock()
+
+	return ct.tree.Next(str)
+}
+
+// Prev returns the largest key strictly less than the string
+func (ct *ConcurrentTree[V]) Prev(str string) (string, V, bool) {
+
+	ct.lock.RLock()
+	defer ct.lock.RUnlock()
+
+	return ct.tree.Prev(str)
+}
+
+// Rank returns the number of keys less than the string
+func (ct *ConcurrentTree[V]) Rank(str string) int {
+
+	ct.lock.RLock()
+	defer ct.lock.RUnlock()
+
+	return ct.tree.Rank(str)
+}
+
+// Select returns the key at the position in key order
+func (ct *ConcurrentTree[V]) Select(position int) (string, V, bool) {
+
+	ct.lock.RLock()
+	defer ct.lock.RUnlock()
+
+	return ct.tree.Select(position)
+}
+
+// String generates an ASCII tree to allow the data structure to be
+// visualised
+func (ct *ConcurrentTree[V]) String() string {
+
+	ct.lock.RLock()
+	defer ct.lock.RUnlock()
+
+	return ct.tree.String()
+}
@@ -0,0 +1,146 @@
+package radix
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Adds and searches from many goroutines at once, run with -race to check
+// for data races
+func TestConcurrentAddAndSearch(t *testing.T) {
+
+	r := NewConcurrentRadixTree()
+	r.Add("somerset road", identifier{"somerset road"})
+
+	writers := 4
+	readers := 8
+	perWriter := 250
+
+	var wg sync.WaitGroup
+
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				key := fmt.Sprintf("road %d-%d", w, i)
+				r.Add(key, identifier{key})
+				if i%10 == 0 {
+					r.Delete(key)
+				}
+			}
+		}(w)
+	}
+
+	for reader := 0; reader < readers; reader++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+
+				keys, content := r.PrefixSearch("road")
+				compareKeysAndContent(keys, content, t)
+
+				keys, content = r.FuzzySearch("som")
+				compareKeysAndContent(keys, content, t)
+
+				for key, value := range r.Fuzzy("rd") {
+					if value != (identifier{key}) {
+						t.Errorf("Key %s did not return valid content", key)
+					}
+				}
+
+				r.Contains("somerset road")
+				r.Next("road")
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	// Every tenth key was deleted again
+	expected := 1 + writers*(perWriter-perWriter/10)
+	if r.Len() != expected {
+		t.Errorf("Expected %d keys, got %d", expected, r.Len())
+	}
+	if count := r.CountPrefix("road"); count != expected-1 {
+		t.Errorf("Expected %d keys under road, got %d", expected-1, count)
+	}
+}
+
+// Updates should be applied atomically, so none are lost
+func TestConcurrentUpdate(t *testing.T) {
+
+	r := NewConcurrentTree[int]()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				r.Update("counter", func(value int, exists bool) int {
+					return value + 1
+				})
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if value, _ := r.Get("counter"); value != 1000 {
+		t.Errorf("Expected counter to be 1000, got %d", value)
+	}
+}
+
+// The tree should be usable from inside a loop over an iterator, even once a
+// change is waiting for the lock
+func TestConcurrentIteratorCallsTree(t *testing.T) {
+
+	r := NewConcurrentTree[int]()
+	for i, key := range []string{"romane", "romanus", "romulus"} {
+		r.Add(key, i)
+	}
+
+	done := make(chan []string)
+	go func() {
+
+		var keys []string
+		for key := range r.Prefix("rom") {
+
+			added := make(chan struct{})
+			go func() {
+				r.Add(key+" road", 0)
+				close(added)
+			}()
+
+			// Give the change time to start waiting if the lock is held
+			time.Sleep(10 * time.Millisecond)
+
+			if !r.Contains(key) {
+				t.Errorf("Expected %s to be found inside the loop", key)
+			}
+			<-added
+
+			keys = append(keys, key)
+		}
+
+		done <- keys
+	}()
+
+	select {
+	case keys := <-done:
+		// Keys added during the iteration aren't seen by it
+		if len(keys) != 3 {
+			t.Errorf("Expected the 3 keys there at the start, got %v", keys)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expected the loop not to deadlock")
+	}
+
+	if r.Len() != 6 {
+		t.Errorf("Expected 6 keys after the loop, got %d", r.Len())
+	}
+}
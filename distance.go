@@ -0,0 +1,195 @@
+package radix
+
+import (
+	"sort"
+	"unicode/utf8"
+)
+
+// DistanceResult is a single key found by an edit distance search
+type DistanceResult[V any] struct {
+	Key     string
+	Content V
+
+	// The number of insertions, deletions, substitutions and transpositions
+	// of letters needed to turn the search into the key
+	Distance int
+}
+
+// SearchWithinDistance finds every key that can be made from the search
+// with at most maxEdits letters inserted, deleted, substituted or swapped
+// with their neighbour (the Damerau-Levenshtein distance). Letters may be
+// edited again after being swapped, so "ca" is 2 from "abc". Unlike the
+// fuzzy search this will tolerate typos such as "smoerset".
+//
+// A row of the edit distance table is worked out for each letter as we
+// descend, so keys sharing a prefix share the work. Whenever every value in
+// the row exceeds maxEdits nothing below can match and the branch is dropped.
+// Results are returned closest first
+func (tree *Tree[V]) SearchWithinDistance(
+	str string,
+	maxEdits int,
+) []DistanceResult[V] {
+
+	results := []DistanceResult[V]{}
+	if maxEdits < 0 {
+		return results
+	}
+
+	query := []rune(string(tree.stringToBytes(str)))
+
+	// The first row is the distance from the empty string
+	row := make([]int, len(query)+1)
+	for i := range row {
+		row[i] = i
+	}
+
+	for _, child := range tree.root.Children() {
+		results = tree.searchDistance(
+			query,
+			maxEdits,
+			child,
+			[][]int{row},
+			map[rune]int{},
+			[]byte{},
+			results)
+	}
+
+	// Stable so that equal distances stay in the order of the tree
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Distance < results[j].Distance
+	})
+
+	return results
+}
+
+// Recursively works through the letters of each node, adding to the results
+// any keys which are within distance. Every row from the root down is kept,
+// along with the last row each letter of the key was seen on, so that swaps
+// of letters any distance apart can be found
+func (tree *Tree[V]) searchDistance(
+	query []rune,
+	maxEdits int,
+	node *treeNode[V],
+	rows [][]int,
+	lastRow map[rune]int,
+	found []byte,
+	results []DistanceResult[V],
+) []DistanceResult[V] {
+
+	// The letters seen here are only on this path, so the rows they were
+	// last seen on before are put back on the way out
+	type seen struct {
+		letter rune
+		row    int
+		ok     bool
+	}
+	var restore []seen
+	defer func() {
+		for i := len(restore) - 1; i >= 0; i-- {
+			if restore[i].ok {
+				lastRow[restore[i].letter] = restore[i].row
+			} else {
+				delete(lastRow, restore[i].letter)
+			}
+		}
+	}()
+
+	key := node.Key()
+
+	for len(key) > 0 {
+
+		letter, size := utf8.DecodeRune(key)
+		key = key[size:]
+
+		// Rows are numbered by the letters of the key, the first is 1
+		i := len(rows)
+		row := rows[i-1]
+
+		next := make([]int, len(query)+1)
+		next[0] = row[0] + 1
+		lowest := next[0]
+
+		// The last letter of the query matching this letter of the key
+		lastCol := 0
+
+		for j := 1; j <= len(query); j++ {
+
+			cost := 1
+			if query[j-1] == letter {
+				cost = 0
+			}
+
+			next[j] = min3(
+				row[j]+1,      // Deletion
+				next[j-1]+1,   // Insertion
+				row[j-1]+cost, // Substitution
+			)
+
+			// Transposition of this letter with the last one in the key
+			// matching this letter of the query, with everything between
+			// them inserted or deleted
+			if k := lastRow[query[j-1]]; k > 0 && lastCol > 0 {
+				swapped := rows[k-1][lastCol-1] + (i - k - 1) + 1 + (j - lastCol - 1)
+				if swapped < next[j] {
+					next[j] = swapped
+				}
+			}
+
+			if cost == 0 {
+				lastCol = j
+			}
+
+			if next[j] < lowest {
+				lowest = next[j]
+			}
+		}
+
+		// Nothing can get closer from here on
+		if lowest > maxEdits {
+			return results
+		}
+
+		rows = append(rows, next)
+
+		previous, ok := lastRow[letter]
+		restore = append(restore, seen{letter, previous, ok})
+		lastRow[letter] = i
+	}
+
+	found = append(found, node.Key()...)
+	row := rows[len(rows)-1]
+
+	if node.Collect() && row[len(query)] <= maxEdits {
+		results = append(results, DistanceResult[V]{
+			Key:      node.originalKey(found),
+			Content:  node.Content(),
+			Distance: row[len(query)],
+		})
+	}
+
+	for _, child := range node.Children() {
+		results = tree.searchDistance(
+			query,
+			maxEdits,
+			child,
+			rows,
+			lastRow,
+			found,
+			results)
+	}
+
+	return results
+}
+
+// Returns the smallest of three numbers
+func min3(a, b, c int) int {
+
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+
+	return a
+}
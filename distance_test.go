@@ -0,0 +1,191 @@
+package radix
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Check a range of typos are found with the distance we would expect
+func TestSearchWithinDistance(t *testing.T) {
+
+	r := buildWikipediaExampleTree()
+	r.Add("somerset", identifier{"somerset"})
+	r.Add("sómerset", identifier{"sómerset"})
+
+	testCases := []struct {
+		Search   string
+		MaxEdits int
+		Expect   []DistanceResult[interface{}]
+	}{
+		{
+			// Transposition
+			Search:   "smoerset",
+			MaxEdits: 1,
+			Expect: []DistanceResult[interface{}]{
+				{Key: "somerset", Content: identifier{"somerset"}, Distance: 1},
+			},
+		},
+		{
+			// A multi-byte letter is a single substitution
+			Search:   "somerset",
+			MaxEdits: 1,
+			Expect: []DistanceResult[interface{}]{
+				{Key: "somerset", Content: identifier{"somerset"}, Distance: 0},
+				{Key: "sómerset", Content: identifier{"sómerset"}, Distance: 1},
+			},
+		},
+		{
+			// Deletion, insertion and substitution
+			Search:   "rubr",
+			MaxEdits: 1,
+			Expect: []DistanceResult[interface{}]{
+				{Key: "ruber", Content: identifier{"ruber"}, Distance: 1},
+			},
+		},
+		{
+			Search:   "romanes",
+			MaxEdits: 2,
+			Expect: []DistanceResult[interface{}]{
+				{Key: "romane", Content: identifier{"romane"}, Distance: 1},
+				{Key: "romanus", Content: identifier{"romanus"}, Distance: 1},
+			},
+		},
+		{
+			Search:   "rubicon",
+			MaxEdits: 0,
+			Expect: []DistanceResult[interface{}]{
+				{Key: "rubicon", Content: identifier{"rubicon"}, Distance: 0},
+			},
+		},
+		{
+			Search:   "xyz",
+			MaxEdits: 1,
+			Expect:   []DistanceResult[interface{}]{},
+		},
+		{
+			Search:   "rubicon",
+			MaxEdits: -1,
+			Expect:   []DistanceResult[interface{}]{},
+		},
+	}
+
+	for _, test := range testCases {
+		res := r.SearchWithinDistance(test.Search, test.MaxEdits)
+		if !reflect.DeepEqual(res, test.Expect) {
+			t.Errorf("Search '%s' within %d gave %+v, expected %+v",
+				test.Search, test.MaxEdits, res, test.Expect)
+		}
+	}
+}
+
+// Typos should still find addresses in the integration tree
+func TestSearchWithinDistanceIntegration(t *testing.T) {
+
+	r := buildIntegrationTree()
+	res := r.SearchWithinDistance("tseco", 1)
+
+	if len(res) == 0 || res[0].Key != "tesco" || res[0].Distance != 1 {
+		t.Errorf("Expected tesco to be found within 1 edit, got %+v", res)
+	}
+}
+
+// The Damerau-Levenshtein distance worked out in full, to check against
+func damerauLevenshtein(a, b []rune) int {
+
+	// The extra first row and column stand in for letters never seen
+	infinity := len(a) + len(b)
+	d := make([][]int, len(a)+2)
+	for i := range d {
+		d[i] = make([]int, len(b)+2)
+	}
+
+	d[0][0] = infinity
+	for i := 0; i <= len(a); i++ {
+		d[i+1][0] = infinity
+		d[i+1][1] = i
+	}
+	for j := 0; j <= len(b); j++ {
+		d[0][j+1] = infinity
+		d[1][j+1] = j
+	}
+
+	lastRow := map[rune]int{}
+	for i := 1; i <= len(a); i++ {
+
+		lastCol := 0
+		for j := 1; j <= len(b); j++ {
+
+			k := lastRow[b[j-1]]
+			l := lastCol
+
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+				lastCol = j
+			}
+
+			d[i+1][j+1] = min(
+				d[i][j]+cost,
+				d[i+1][j]+1,
+				d[i][j+1]+1,
+				d[k][l]+(i-k-1)+1+(j-l-1),
+			)
+		}
+
+		lastRow[a[i-1]] = i
+	}
+
+	return d[len(a)+1][len(b)+1]
+}
+
+// Letters may be edited again after being swapped, which isn't possible
+// with only neighbouring swaps
+func TestSearchWithinDistanceSwapThenEdit(t *testing.T) {
+
+	r := NewTree[int]()
+	r.Add("abc", 1)
+
+	res := r.SearchWithinDistance("ca", 2)
+	if len(res) != 1 || res[0].Key != "abc" || res[0].Distance != 2 {
+		t.Errorf("Expected abc to be 2 from ca, got %+v", res)
+	}
+
+	if res := r.SearchWithinDistance("ca", 1); len(res) != 0 {
+		t.Errorf("Expected abc not to be within 1 of ca, got %+v", res)
+	}
+}
+
+// Every key should be found at the distance worked out in full
+func TestSearchWithinDistanceExhaustive(t *testing.T) {
+
+	keys := []string{
+		"abc", "acb", "bca", "cab", "abcabc", "aabbcc", "cbacba", "ab", "ba",
+		"a", "abcd", "badc", "dcba", "caab",
+	}
+	searches := []string{"ca", "abc", "bac", "cba", "acbd", "bcaa", "d", "aabcc"}
+
+	r := NewTree[int]()
+	for i, key := range keys {
+		r.Add(key, i)
+	}
+
+	for _, search := range searches {
+		for maxEdits := 0; maxEdits <= 3; maxEdits++ {
+
+			found := map[string]int{}
+			for _, result := range r.SearchWithinDistance(search, maxEdits) {
+				found[result.Key] = result.Distance
+			}
+
+			for _, key := range keys {
+				expected := damerauLevenshtein([]rune(search), []rune(key))
+				distance, ok := found[key]
+
+				if ok != (expected <= maxEdits) || (ok && distance != expected) {
+					t.Errorf("Expected %s to be %d from %s within %d, got %d %t",
+						key, expected, search, maxEdits, distance, ok)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,494 @@
+package radix
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// A DurableTree keeps two files in its directory, a snapshot of the tree in
+// the binary format and a log of every change made since. Each record in the
+// log is framed by its length and a CRC-32, then holds the operation, the key
+// and (for adds) the encoded content
+const (
+	durableSnapshot = "snapshot"
+	durableLog      = "wal"
+
+	durableAdd          = 1
+	durableDelete       = 2
+	durableDeletePrefix = 3
+
+	durableFrameSize = 8
+)
+
+// ErrDurableClosed is returned when using a DurableTree after closing it
+var ErrDurableClosed = errors.New("Durable tree has been closed")
+
+// DurableOptions are the settings of a DurableTree
+type DurableOptions[V any] struct {
+
+	// The number of changes to log before the snapshot is rewritten and the
+	// log emptied. If 0 this only happens on Compact
+	CompactAfter int
+
+	// Encodes the content in the snapshot and log, gob if not set
+	Codec Codec[V]
+
+	// The options the tree is made with, as given to NewTree
+	TreeOptions []Option
+}
+
+// The file the log is kept in, so that tests can make writing to it fail
+type durableFile interface {
+	io.ReadWriteSeeker
+	Truncate(size int64) error
+	Sync() error
+	Close() error
+}
+
+// DurableTree is a tree whose changes survive the process ending. Every
+// change is appended to a write-ahead log and synced to disk before it is
+// applied, so once a change returns it won't be lost. Opening the tree reads
+// the last snapshot and replays the log on top of it.
+//
+// Changes only ever set or remove keys, so replaying a change which is
+// already in the snapshot does no harm. This means a crash part way through
+// compacting loses nothing.
+//
+// An error from a change always means the change wasn't made. Compacting
+// after a change is only tidying up, so if it fails the change still stands
+// and compacting is tried again after the next one. The error is kept until
+// then, and returned by Close if it is never put right.
+//
+// It is safe to use from many goroutines, changes wait for searches to finish
+type DurableTree[V any] struct {
+	lock sync.RWMutex
+	dir  string
+	opts DurableOptions[V]
+
+	tree       *Tree[V]
+	log        durableFile
+	entries    int
+	compactErr error
+}
+
+// OpenDurable opens the durable tree kept in the directory, creating it if
+// it doesn't exist
+func OpenDurable[V any](
+	dir string,
+	opts DurableOptions[V],
+) (*DurableTree[V], error) {
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	dt := &DurableTree[V]{
+		dir:  dir,
+		opts: opts,
+		tree: NewTree[V](opts.TreeOptions...),
+	}
+	dt.tree.SetCodec(opts.Codec)
+
+	if err := dt.readSnapshot(); err != nil {
+		return nil, err
+	}
+
+	log, err := os.OpenFile(
+		filepath.Join(dir, durableLog),
+		os.O_RDWR|os.O_CREATE,
+		0o644)
+
+	if err != nil {
+		return nil, err
+	}
+
+	dt.log = log
+	if err := dt.replay(); err != nil {
+		log.Close()
+		return nil, err
+	}
+
+	return dt, nil
+}
+
+// Loads the snapshot if there is one
+func (dt *DurableTree[V]) readSnapshot() error {
+
+	f, err := os.Open(filepath.Join(dt.dir, durableSnapshot))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = dt.tree.ReadFrom(bufio.NewReader(f))
+	return err
+}
+
+// Applies every record in the log to the tree. A record which is cut short
+// or fails its checksum was being written when the process ended, so it
+// never took effect. The log is cut back to the last whole record
+func (dt *DurableTree[V]) replay() error {
+
+	r := bufio.NewReader(dt.log)
+	var good int64
+
+	for {
+		frame := make([]byte, durableFrameSize)
+		if _, err := io.ReadFull(r, frame); err != nil {
+			break
+		}
+
+		length := binary.LittleEndian.Uint32(frame[0:])
+		if length > binaryMaxChunk {
+			break
+		}
+
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(r, payload); err != nil {
+			break
+		}
+
+		if crc32.ChecksumIEEE(payload) != binary.LittleEndian.Uint32(frame[4:]) {
+			break
+		}
+
+		if err := dt.apply(payload); err != nil {
+			return err
+		}
+
+		good += int64(durableFrameSize + len(payload))
+		dt.entries++
+	}
+
+	if err := dt.log.Truncate(good); err != nil {
+		return err
+	}
+
+	_, err := dt.log.Seek(good, io.SeekStart)
+	return err
+}
+
+// Applies a single logged change to the tree
+func (dt *DurableTree[V]) apply(payload []byte) error {
+
+	if len(payload) < 1 {
+		return ErrInvalidFormat
+	}
+
+	keyLength, n := binary.Uvarint(payload[1:])
+	if n <= 0 || keyLength > uint64(len(payload)-1-n) {
+		return ErrInvalidFormat
+	}
+
+	key := string(payload[1+n : 1+n+int(keyLength)])
+	rest := payload[1+n+int(keyLength):]
+
+	switch payload[0] {
+	case durableAdd:
+		content, err := dt.tree.contentCodec().Decode(rest)
+		if err != nil {
+			return err
+		}
+		dt.tree.Add(key, content)
+	case durableDelete:
+		dt.tree.Delete(key)
+	case durableDeletePrefix:
+		dt.tree.DeletePrefix(key)
+	default:
+		return ErrInvalidFormat
+	}
+
+	return nil
+}
+
+// Appends a change to the log and syncs it to disk. If that fails the log
+// is cut back to where it was, so that a torn record can't hide the records
+// after it and a failed change is never replayed. If even that fails the log
+// can't be trusted, so it is closed
+func (dt *DurableTree[V]) append(op byte, key string, content []byte) error {
+
+	if dt.log == nil {
+		return ErrDurableClosed
+	}
+
+	offset, err := dt.log.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+
+	payload := []byte{op}
+	payload = binary.AppendUvarint(payload, uint64(len(key)))
+	payload = append(payload, key...)
+	payload = append(payload, content...)
+
+	record := make([]byte, durableFrameSize, durableFrameSize+len(payload))
+	binary.LittleEndian.PutUint32(record[0:], uint32(len(payload)))
+	binary.LittleEndian.PutUint32(record[4:], crc32.ChecksumIEEE(payload))
+	record = append(record, payload...)
+
+	_, err = dt.log.Write(record)
+	if err == nil {
+		err = dt.log.Sync()
+	}
+
+	if err != nil {
+		dt.rollback(offset)
+		return err
+	}
+
+	dt.entries++
+	return nil
+}
+
+// Cuts the log back to the offset after a failed append, closing it if that
+// can't be done
+func (dt *DurableTree[V]) rollback(offset int64) {
+
+	err := dt.log.Truncate(offset)
+	if err == nil {
+		_, err = dt.log.Seek(offset, io.SeekStart)
+	}
+	if err == nil {
+		err = dt.log.Sync()
+	}
+
+	if err != nil {
+		dt.log.Close()
+		dt.log = nil
+	}
+}
+
+// Compacts once enough changes have been logged. The change before it has
+// already been made, so a failure is kept rather than returned
+func (dt *DurableTree[V]) maybeCompact() {
+
+	if dt.opts.CompactAfter > 0 && dt.entries >= dt.opts.CompactAfter {
+		dt.compactErr = dt.compact()
+	}
+}
+
+// Add inserts a key, replacing the content if it exists. Returns whether the
+// key was new
+func (dt *DurableTree[V]) Add(str string, content V) (bool, error) {
+
+	dt.lock.Lock()
+	defer dt.lock.Unlock()
+
+	// Empty keys are never added, so there is nothing to log
+	if str == "" {
+		return false, nil
+	}
+
+	encoded, err := dt.tree.contentCodec().Encode(content)
+	if err != nil {
+		return false, err
+	}
+
+	if err := dt.append(durableAdd, str, encoded); err != nil {
+		return false, err
+	}
+
+	_, isNew := dt.tree.Add(str, content)
+	dt.maybeCompact()
+
+	return isNew, nil
+}
+
+// Delete removes a single key, returning whether it was there to be removed
+func (dt *DurableTree[V]) Delete(str string) (bool, error) {
+
+	dt.lock.Lock()
+	defer dt.lock.Unlock()
+
+	if !dt.tree.Contains(str) {
+		return false, nil
+	}
+
+	if err := dt.append(durableDelete, str, nil); err != nil {
+		return false, err
+	}
+
+	dt.tree.Delete(str)
+	dt.maybeCompact()
+
+	return true, nil
+}
+
+// DeletePrefix removes every key beginning with the prefix, returning the
+// number of keys which were removed
+func (dt *DurableTree[V]) DeletePrefix(str string) (int, error) {
+
+	dt.lock.Lock()
+	defer dt.lock.Unlock()
+
+	if dt.tree.CountPrefix(str) == 0 {
+		return 0, nil
+	}
+
+	if err := dt.append(durableDeletePrefix, str, nil); err != nil {
+		return 0, err
+	}
+
+	removed := dt.tree.DeletePrefix(str)
+	dt.maybeCompact()
+
+	return removed, nil
+}
+
+// Compact writes a fresh snapshot of the tree and empties the log
+func (dt *DurableTree[V]) Compact() error {
+
+	dt.lock.Lock()
+	defer dt.lock.Unlock()
+
+	dt.compactErr = dt.compact()
+	return dt.compactErr
+}
+
+// The snapshot is written to a temporary file which replaces the old one
+// once it is safely on disk, so there is always a whole snapshot to open.
+// Only then is the log emptied
+func (dt *DurableTree[V]) compact() error {
+
+	if dt.log == nil {
+		return ErrDurableClosed
+	}
+
+	path := filepath.Join(dt.dir, durableSnapshot)
+	tmp := path + ".tmp"
+
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	_, err = dt.tree.WriteTo(f)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	syncDir(dt.dir)
+
+	if err := dt.log.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := dt.log.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if err := dt.log.Sync(); err != nil {
+		return err
+	}
+
+	dt.entries = 0
+	return nil
+}
+
+// Syncs a directory so that a rename within it is on disk. Not every
+// platform can, where it can't the rename is left to the file system
+func syncDir(dir string) {
+
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+
+	d.Sync()
+	d.Close()
+}
+
+// Close closes the log, the tree can't be used afterwards. Everything is
+// already on disk so nothing is lost, though the error from the last
+// compaction is returned if it failed
+func (dt *DurableTree[V]) Close() error {
+
+	dt.lock.Lock()
+	defer dt.lock.Unlock()
+
+	if dt.log == nil {
+		return ErrDurableClosed
+	}
+
+	err := dt.log.Close()
+	dt.log = nil
+
+	if err == nil {
+		err = dt.compactErr
+	}
+
+	return err
+}
+
+// Get returns the content stored against an exact key
+func (dt *DurableTree[V]) Get(str string) (V, bool) {
+
+	dt.lock.RLock()
+	defer dt.lock.RUnlock()
+
+	return dt.tree.Get(str)
+}
+
+// Contains checks whether the exact key has been added to the tree
+func (dt *DurableTree[V]) Contains(str string) bool {
+
+	dt.lock.RLock()
+	defer dt.lock.RUnlock()
+
+	return dt.tree.Contains(str)
+}
+
+// Len returns the number of distinct keys held in the tree
+func (dt *DurableTree[V]) Len() int {
+
+	dt.lock.RLock()
+	defer dt.lock.RUnlock()
+
+	return dt.tree.Len()
+}
+
+// PrefixSearch returns every key beginning with the prefix
+func (dt *DurableTree[V]) PrefixSearch(str string) ([]string, []V) {
+
+	dt.lock.RLock()
+	defer dt.lock.RUnlock()
+
+	return dt.tree.PrefixSearch(str)
+}
+
+// FuzzySearch returns every key matching the fuzzy search
+func (dt *DurableTree[V]) FuzzySearch(str string) ([]string, []V) {
+
+	dt.lock.RLock()
+	defer dt.lock.RUnlock()
+
+	return dt.tree.FuzzySearch(str)
+}
+
+// Snapshot returns the tree as it is now, for anything else that's needed.
+// Later changes aren't seen by it
+func (dt *DurableTree[V]) Snapshot() *ImmutableTree[V] {
+
+	// Freezing gives the tree a new generation, so this is a change
+	dt.lock.Lock()
+	defer dt.lock.Unlock()
+
+	return dt.tree.Freeze()
+}
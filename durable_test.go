@@ -0,0 +1,308 @@
+package radix
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// Opens a durable tree, failing the test if it can't be
+func openDurable(dir string, compactAfter int, t *testing.T) *DurableTree[int] {
+
+	dt, err := OpenDurable(dir, DurableOptions[int]{CompactAfter: compactAfter})
+	if err != nil {
+		t.Fatalf("Expected to open the durable tree, got %s", err)
+	}
+
+	return dt
+}
+
+// Checks the keys and content held by a durable tree
+func expectDurable(
+	dt *DurableTree[int],
+	expectedKeys []string,
+	expectedContent []int,
+	t *testing.T,
+) {
+
+	t.Helper()
+
+	keys, content := dt.PrefixSearch("")
+	if !slices.Equal(keys, expectedKeys) {
+		t.Errorf("Expected keys %v, got %v", expectedKeys, keys)
+	}
+	if !slices.Equal(content, expectedContent) {
+		t.Errorf("Expected content %v, got %v", expectedContent, content)
+	}
+	if dt.Len() != len(expectedKeys) {
+		t.Errorf("Expected %d keys, got %d", len(expectedKeys), dt.Len())
+	}
+}
+
+// Changes should be replayed from the log when the tree is opened again
+func TestDurableReplay(t *testing.T) {
+
+	dir := t.TempDir()
+
+	dt := openDurable(dir, 0, t)
+	dt.Add("romane", 1)
+	dt.Add("romanus", 2)
+	dt.Add("romulus", 3)
+	dt.Add("rubens", 4)
+	dt.Add("romane", 10)
+
+	if removed, err := dt.Delete("romanus"); !removed || err != nil {
+		t.Errorf("Expected romanus to be removed, got %t %v", removed, err)
+	}
+	if removed, _ := dt.Delete("ruber"); removed {
+		t.Errorf("Expected ruber not to be removed")
+	}
+	if removed, _ := dt.DeletePrefix("rub"); removed != 1 {
+		t.Errorf("Expected 1 key to be removed, got %d", removed)
+	}
+
+	if err := dt.Close(); err != nil {
+		t.Fatalf("Expected to close the tree, got %s", err)
+	}
+	if _, err := dt.Add("ruber", 5); err != ErrDurableClosed {
+		t.Errorf("Expected adding after close to fail, got %v", err)
+	}
+
+	dt = openDurable(dir, 0, t)
+	defer dt.Close()
+
+	expectDurable(dt, []string{"romane", "romulus"}, []int{10, 3}, t)
+}
+
+// Compacting writes a snapshot and empties the log, after which the tree
+// opens from the snapshot
+func TestDurableCompaction(t *testing.T) {
+
+	dir := t.TempDir()
+
+	dt := openDurable(dir, 3, t)
+	dt.Add("romane", 1)
+	dt.Add("romanus", 2)
+
+	if _, err := os.Stat(filepath.Join(dir, durableSnapshot)); !os.IsNotExist(err) {
+		t.Errorf("Expected no snapshot before compacting")
+	}
+
+	// The third change compacts
+	dt.Add("romulus", 3)
+
+	if _, err := os.Stat(filepath.Join(dir, durableSnapshot)); err != nil {
+		t.Errorf("Expected a snapshot after compacting, got %s", err)
+	}
+	if info, _ := os.Stat(filepath.Join(dir, durableLog)); info.Size() != 0 {
+		t.Errorf("Expected an empty log after compacting, got %d bytes",
+			info.Size())
+	}
+
+	dt.Delete("romane")
+	dt.Close()
+
+	dt = openDurable(dir, 3, t)
+	defer dt.Close()
+
+	expectDurable(dt, []string{"romanus", "romulus"}, []int{2, 3}, t)
+
+	if err := dt.Compact(); err != nil {
+		t.Errorf("Expected to compact, got %s", err)
+	}
+	expectDurable(dt, []string{"romanus", "romulus"}, []int{2, 3}, t)
+}
+
+// A record cut short by a crash was never applied, so it is dropped and
+// the log carries on from the last whole record
+func TestDurableTornWrite(t *testing.T) {
+
+	dir := t.TempDir()
+
+	dt := openDurable(dir, 0, t)
+	dt.Add("romane", 1)
+	dt.Add("romanus", 2)
+	dt.Close()
+
+	path := filepath.Join(dir, durableLog)
+	info, _ := os.Stat(path)
+
+	// Cut the last record short
+	os.Truncate(path, info.Size()-3)
+
+	dt = openDurable(dir, 0, t)
+	expectDurable(dt, []string{"romane"}, []int{1}, t)
+
+	dt.Add("romulus", 3)
+	dt.Close()
+
+	dt = openDurable(dir, 0, t)
+	defer dt.Close()
+
+	expectDurable(dt, []string{"romane", "romulus"}, []int{1, 3}, t)
+}
+
+// A log which fails the next write part way through, the next sync or
+// every truncate
+type failingFile struct {
+	durableFile
+	failWrite    bool
+	failSync     bool
+	failTruncate bool
+}
+
+var errFailing = errors.New("Failing on purpose")
+
+func (f *failingFile) Write(data []byte) (int, error) {
+
+	if f.failWrite {
+		f.failWrite = false
+		n, _ := f.durableFile.Write(data[:len(data)/2])
+		return n, errFailing
+	}
+
+	return f.durableFile.Write(data)
+}
+
+func (f *failingFile) Sync() error {
+
+	if f.failSync {
+		f.failSync = false
+		return errFailing
+	}
+
+	return f.durableFile.Sync()
+}
+
+func (f *failingFile) Truncate(size int64) error {
+
+	if f.failTruncate {
+		return errFailing
+	}
+
+	return f.durableFile.Truncate(size)
+}
+
+// A change which fails to be logged must leave nothing behind, so that the
+// changes logged after it survive and it is never replayed itself
+func TestDurableFailedAppend(t *testing.T) {
+
+	dir := t.TempDir()
+
+	dt := openDurable(dir, 0, t)
+	dt.Add("romane", 1)
+
+	failing := &failingFile{durableFile: dt.log, failWrite: true}
+	dt.log = failing
+
+	if _, err := dt.Add("romanus", 2); err != errFailing {
+		t.Errorf("Expected the torn write to fail, got %v", err)
+	}
+	if _, err := dt.Add("romulus", 3); err != nil {
+		t.Errorf("Expected the next add to succeed, got %s", err)
+	}
+
+	failing.failSync = true
+	if _, err := dt.Delete("romane"); err != errFailing {
+		t.Errorf("Expected the failed sync to fail, got %v", err)
+	}
+	if _, err := dt.Add("rubens", 4); err != nil {
+		t.Errorf("Expected the next add to succeed, got %s", err)
+	}
+
+	// If the log can't be cut back it can't be trusted, so the tree closes
+	failing.failWrite = true
+	failing.failTruncate = true
+	if _, err := dt.Add("ruber", 5); err != errFailing {
+		t.Errorf("Expected the torn write to fail, got %v", err)
+	}
+	if _, err := dt.Add("ruber", 5); err != ErrDurableClosed {
+		t.Errorf("Expected the tree to be closed, got %v", err)
+	}
+
+	dt = openDurable(dir, 0, t)
+	defer dt.Close()
+
+	expectDurable(dt, []string{"romane", "romulus", "rubens"}, []int{1, 3, 4}, t)
+}
+
+// A failed compaction happens after the change is made, so the change still
+// succeeds and compacting is tried again after the next one
+func TestDurableFailedCompaction(t *testing.T) {
+
+	dir := t.TempDir()
+
+	// A directory in the way of the new snapshot stops it being written
+	blocked := filepath.Join(dir, durableSnapshot+".tmp", "blocked")
+	if err := os.MkdirAll(blocked, 0o755); err != nil {
+		t.Fatalf("Expected to block the snapshot, got %s", err)
+	}
+
+	dt := openDurable(dir, 2, t)
+	dt.Add("romane", 1)
+
+	if _, err := dt.Add("romanus", 2); err != nil {
+		t.Errorf("Expected the add to succeed when compacting fails, got %s", err)
+	}
+	if removed, err := dt.DeletePrefix("romane"); err != nil || removed != 1 {
+		t.Errorf("Expected the delete to succeed when compacting fails, got %d %v",
+			removed, err)
+	}
+	expectDurable(dt, []string{"romanus"}, []int{2}, t)
+
+	if err := dt.Close(); err == nil {
+		t.Errorf("Expected close to report the failed compaction")
+	}
+
+	// Compacting is tried again after the next change
+	dt = openDurable(dir, 2, t)
+	expectDurable(dt, []string{"romanus"}, []int{2}, t)
+
+	os.RemoveAll(filepath.Dir(blocked))
+
+	if _, err := dt.Add("romulus", 3); err != nil {
+		t.Errorf("Expected the add to succeed, got %s", err)
+	}
+	if info, _ := os.Stat(filepath.Join(dir, durableLog)); info.Size() != 0 {
+		t.Errorf("Expected an empty log after compacting, got %d bytes",
+			info.Size())
+	}
+	if err := dt.Close(); err != nil {
+		t.Errorf("Expected to close, got %s", err)
+	}
+
+	dt = openDurable(dir, 2, t)
+	defer dt.Close()
+
+	expectDurable(dt, []string{"romanus", "romulus"}, []int{2, 3}, t)
+}
+
+// A crash after the snapshot is written but before the log is emptied means
+// the log is replayed over a snapshot which already holds it
+func TestDurableCrashDuringCompaction(t *testing.T) {
+
+	dir := t.TempDir()
+
+	dt := openDurable(dir, 0, t)
+	dt.Add("romane", 1)
+	dt.Add("romanus", 2)
+	dt.DeletePrefix("roma")
+	dt.Add("romanus", 3)
+	dt.Add("romulus", 4)
+	dt.Delete("romulus")
+
+	logPath := filepath.Join(dir, durableLog)
+	log, _ := os.ReadFile(logPath)
+
+	dt.Compact()
+	dt.Close()
+
+	os.WriteFile(logPath, log, 0o644)
+
+	dt = openDurable(dir, 0, t)
+	defer dt.Close()
+
+	expectDurable(dt, []string{"romanus"}, []int{3}, t)
+}
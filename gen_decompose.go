@@ -0,0 +1,92 @@
+//go:build generate
+
+// Generates decompose.go, the letters each accented letter is based on. Run
+// with go generate, which calls:
+//
+//	go run -tags generate gen_decompose.go
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Only the Basic Multilingual Plane is covered, it holds every script which
+// builds letters from marks
+const maxRune = 0xFFFF
+
+// Tatweel only stretches Arabic script, it's no letter to be left with
+const tatweel = 0x0640
+
+func main() {
+
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, `// Code generated by gen_decompose.go; DO NOT EDIT.
+
+package radix
+
+// The letters each letter decomposes into once its accents are dropped, for
+// every letter in the Basic Multilingual Plane whose Unicode decomposition
+// (canonical or compatibility, as NFKD) holds combining marks. Generated
+// from the Unicode %s decompositions, it covers Latin (including
+// Vietnamese), Greek, Cyrillic and the other scripts which build letters
+// from marks. A few letters decompose into more than one, such as ǆ
+var accentBases = map[rune]string{
+`, norm.Version)
+
+	for r := rune(0x80); r <= maxRune; r++ {
+		if bases, ok := accentBases(r); ok {
+			fmt.Fprintf(&buf, "0x%04X: %q,\n", r, bases)
+		}
+	}
+
+	buf.WriteString("}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := os.WriteFile("decompose.go", src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// Returns the letters a letter decomposes into without its marks, if it has
+// any marks to drop. Letters which would be left as something other than
+// letters (a space or tatweel) are skipped
+func accentBases(r rune) (string, bool) {
+
+	if !unicode.IsLetter(r) {
+		return "", false
+	}
+
+	marks := false
+	bases := []rune{}
+
+	for _, d := range norm.NFKD.String(string(r)) {
+
+		if unicode.Is(unicode.Mn, d) {
+			marks = true
+			continue
+		}
+
+		if !unicode.IsLetter(d) || d == tatweel {
+			return "", false
+		}
+		bases = append(bases, d)
+	}
+
+	if !marks || len(bases) == 0 {
+		return "", false
+	}
+
+	return string(bases), true
+}
@@ -0,0 +1,5 @@
+module github.com/Ganners/go-radix
+
+go 1.23.0
+
+require golang.org/x/text v0.28.0
@@ -0,0 +1,271 @@
+package radix
+
+import (
+	"iter"
+	"sync/atomic"
+)
+
+// Every tree and snapshot takes its own generation from here, so that no two
+// ever believe they own the same node
+var generations atomic.Uint64
+
+// Returns a generation which hasn't been handed out before
+func nextGeneration() uint64 {
+	return generations.Add(1)
+}
+
+// Snapshot returns a copy of the tree in O(1) time. The two share all of
+// their nodes, and from then on either can be changed without the other
+// seeing it. Only the nodes along the path of a change are copied (the rest
+// remain shared), so a snapshot can be searched by other goroutines while
+// the original carries on being changed
+func (tree *Tree[V]) Snapshot() *Tree[V] {
+
+	snapshot := tree.fork()
+
+	// The original no longer owns the nodes it shares either
+	tree.generation = nextGeneration()
+
+	return snapshot
+}
+
+// Returns a new tree sharing every node with this one, under a generation of
+// its own. The original must not be changed afterwards unless it is given a
+// new generation too
+func (tree *Tree[V]) fork() *Tree[V] {
+
+	forked := *tree
+	forked.generation = nextGeneration()
+
+	return &forked
+}
+
+// Freeze returns an immutable snapshot of the tree
+func (tree *Tree[V]) Freeze() *ImmutableTree[V] {
+	return &ImmutableTree[V]{
+		tree: tree.Snapshot(),
+	}
+}
+
+// ImmutableTree is a version of a tree which never changes. Adding or
+// deleting returns a new version, sharing every node which wasn't on the
+// path of the change with the version before it. This makes it safe to
+// search from any number of goroutines without locking, while the next
+// version is built
+type ImmutableTree[V any] struct {
+	tree *Tree[V]
+}
+
+// ImmutableRadixTree is an ImmutableTree which can hold content of any type
+type ImmutableRadixTree = ImmutableTree[interface{}]
+
+// NewImmutableTree returns an empty ImmutableTree for content of type V
+func NewImmutableTree[V any](opts ...Option) *ImmutableTree[V] {
+	return &ImmutableTree[V]{
+		tree: NewTree[V](opts...),
+	}
+}
+
+// NewImmutableRadixTree returns an empty ImmutableRadixTree
+func NewImmutableRadixTree(opts ...Option) *ImmutableRadixTree {
+	return NewImmutableTree[interface{}](opts...)
+}
+
+// Add returns a new version with the key inserted (replacing the content if
+// it exists) and whether the key was new
+func (it *ImmutableTree[V]) Add(
+	str string,
+	content V,
+) (*ImmutableTree[V], bool) {
+
+	next := it.tree.fork()
+	_, isNew := next.Add(str, content)
+
+	return &ImmutableTree[V]{tree: next}, isNew
+}
+
+// Delete returns a new version without the key, and whether the key was
+// there to be removed. If it wasn't the same version is returned
+func (it *ImmutableTree[V]) Delete(str string) (*ImmutableTree[V], bool) {
+
+	if !it.tree.Contains(str) {
+		return it, false
+	}
+
+	next := it.tree.fork()
+	next.Delete(str)
+
+	return &ImmutableTree[V]{tree: next}, true
+}
+
+// DeletePrefix returns a new version without any of the keys beginning with
+// the prefix, and the number of keys which were removed
+func (it *ImmutableTree[V]) DeletePrefix(str string) (*ImmutableTree[V], int) {
+
+	next := it.tree.fork()
+	removed := next.DeletePrefix(str)
+
+	return &ImmutableTree[V]{tree: next}, removed
+}
+
+// Tree returns a tree holding the same keys which can be changed without
+// affecting this version, useful for making many changes at once before
+// freezing it again
+func (it *ImmutableTree[V]) Tree() *Tree[V] {
+	return it.tree.fork()
+}
+
+// Get returns the content stored against an exact key
+func (it *ImmutableTree[V]) Get(str string) (V, bool) {
+	return it.tree.Get(str)
+}
+
+// Contains checks whether the exact key is in this version
+func (it *ImmutableTree[V]) Contains(str string) bool {
+	return it.tree.Contains(str)
+}
+
+// Len returns the number of distinct keys held in this version
+func (it *ImmutableTree[V]) Len() int {
+	return it.tree.Len()
+}
+
+// CountPrefix returns the number of keys beginning with the prefix
+func (it *ImmutableTree[V]) CountPrefix(str string) int {
+	return it.tree.CountPrefix(str)
+}
+
+// LongestPrefix returns the longest key that is a prefix of the string
+func (it *ImmutableTree[V]) LongestPrefix(str string) (string, bool) {
+	return it.tree.LongestPrefix(str)
+}
+
+// PrefixSearch returns every key beginning with the prefix
+func (it *ImmutableTree[V]) PrefixSearch(str string) ([]string, []V) {
+	return it.tree.PrefixSearch(str)
+}
+
+// PrefixSearchWithOptions performs a prefix search bounded by the options
+func (it *ImmutableTree[V]) PrefixSearchWithOptions(
+	str string,
+	opts SearchOptions,
+) ([]string, []V) {
+	return it.tree.PrefixSearchWithOptions(str, opts)
+}
+
+// FuzzySearch returns every key matching the fuzzy search
+func (it *ImmutableTree[V]) FuzzySearch(str string) ([]string, []V) {
+	return it.tree.FuzzySearch(str)
+}
+
+// FuzzySearchWithOptions performs a fuzzy search bounded by the options
+func (it *ImmutableTree[V]) FuzzySearchWithOptions(
+	str string,
+	opts SearchOptions,
+) ([]string, []V) {
+	return it.tree.FuzzySearchWithOptions(str, opts)
+}
+
+// FuzzySearchRanked returns the fuzzy search results best first
+func (it *ImmutableTree[V]) FuzzySearchRanked(
+	str string,
+	opts SearchOptions,
+) []FuzzyResult[V] {
+	return it.tree.FuzzySearchRanked(str, opts)
+}
+
+// SearchWithinDistance finds every key within maxEdits of the string
+func (it *ImmutableTree[V]) SearchWithinDistance(
+	str string,
+	maxEdits int,
+) []DistanceResult[V] {
+	return it.tree.SearchWithinDistance(str, maxEdits)
+}
+
+// WalkPrefix calls the function for every key beginning with the prefix
+func (it *ImmutableTree[V]) WalkPrefix(
+	str string,
+	fn func(key string, value V) bool,
+) {
+	it.tree.WalkPrefix(str, fn)
+}
+
+// WalkFuzzy calls the function for every key matching the fuzzy search
+func (it *ImmutableTree[V]) WalkFuzzy(
+	str string,
+	fn func(key string, value V) bool,
+) {
+	it.tree.WalkFuzzy(str, fn)
+}
+
+// Range calls the function for every key between from and to
+func (it *ImmutableTree[V]) Range(
+	from string,
+	to string,
+	fromInclusive bool,
+	toInclusive bool,
+	fn func(key string, value V) bool,
+) {
+	it.tree.Range(from, to, fromInclusive, toInclusive, fn)
+}
+
+// All returns an iterator over every key
+func (it *ImmutableTree[V]) All() iter.Seq2[string, V] {
+	return it.tree.All()
+}
+
+// Prefix returns an iterator over every key beginning with the prefix
+func (it *ImmutableTree[V]) Prefix(str string) iter.Seq2[string, V] {
+	return it.tree.Prefix(str)
+}
+
+// Fuzzy returns an iterator over every key matching the fuzzy search
+func (it *ImmutableTree[V]) Fuzzy(str string) iter.Seq2[string, V] {
+	return it.tree.Fuzzy(str)
+}
+
+// Min returns the smallest key in this version
+func (it *ImmutableTree[V]) Min() (string, V, bool) {
+	return it.tree.Min()
+}
+
+// Max returns the largest key in this version
+func (it *ImmutableTree[V]) Max() (string, V, bool) {
+	return it.tree.Max()
+}
+
+// Floor returns the largest key less than or equal to the string
+func (it *ImmutableTree[V]) Floor(str string) (string, V, bool) {
+	return it.tree.Floor(str)
+}
+
+// Ceiling returns the smallest key greater than or equal to the string
+func (it *ImmutableTree[V]) Ceiling(str string) (string, V, bool) {
+	return it.tree.Ceiling(str)
+}
+
+// Next returns the smallest key strictly greater than the string
+func (it *ImmutableTree[V]) Next(str string) (string, V, bool) {
+	return it.tree.Next(str)
+}
+
+// Prev returns the largest key strictly less than the string
+func (it *ImmutableTree[V]) Prev(str string) (string, V, bool) {
+	return it.tree.Prev(str)
+}
+
+// Rank returns the number of keys less than the string
+func (it *ImmutableTree[V]) Rank(str string) int {
+	return it.tree.Rank(str)
+}
+
+// Select returns the key at the position in key order
+func (it *ImmutableTree[V]) Select(position int) (string, V, bool) {
+	return it.tree.Select(position)
+}
+
+// String generates an ASCII tree to allow the data structure to be
+// visualised
+func (it *ImmutableTree[V]) String() string {
+	return it.tree.String()
+}
@@ -0,0 +1,211 @@
+package radix
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// Changes to a tree after a snapshot is taken should not be seen by the
+// snapshot, and changes to the snapshot should not be seen by the tree
+func TestSnapshotIsolation(t *testing.T) {
+
+	r := NewTree[int]()
+	r.Add("romane", 1)
+	r.Add("romanus", 2)
+	r.Add("romulus", 3)
+
+	snapshot := r.Snapshot()
+
+	r.Add("rubens", 4)
+	r.Add("roman", 5)
+	r.Replace("romulus", 30)
+	r.Delete("romanus")
+
+	snapshot.Add("ruber", 6)
+	snapshot.DeletePrefix("romu")
+
+	keys, content := r.PrefixSearch("")
+	expectedKeys := []string{"roman", "romane", "romulus", "rubens"}
+	if !slices.Equal(keys, expectedKeys) {
+		t.Errorf("Expected tree keys %v, got %v", expectedKeys, keys)
+	}
+	if !slices.Equal(content, []int{5, 1, 30, 4}) {
+		t.Errorf("Expected tree content [5 1 30 4], got %v", content)
+	}
+
+	keys, content = snapshot.PrefixSearch("")
+	expectedKeys = []string{"romane", "romanus", "ruber"}
+	if !slices.Equal(keys, expectedKeys) {
+		t.Errorf("Expected snapshot keys %v, got %v", expectedKeys, keys)
+	}
+	if !slices.Equal(content, []int{1, 2, 6}) {
+		t.Errorf("Expected snapshot content [1 2 6], got %v", content)
+	}
+
+	if r.Len() != 4 || snapshot.Len() != 3 {
+		t.Errorf("Expected lengths of 4 and 3, got %d and %d",
+			r.Len(), snapshot.Len())
+	}
+
+	// The counts and bit masks must be those of each version
+	if count := snapshot.CountPrefix("rom"); count != 2 {
+		t.Errorf("Expected 2 snapshot keys under rom, got %d", count)
+	}
+	if keys, _ := snapshot.FuzzySearch("rmls"); len(keys) != 0 {
+		t.Errorf("Expected no snapshot keys for rmls, got %v", keys)
+	}
+	if keys, _ := r.FuzzySearch("rmls"); !slices.Equal(keys, []string{"romulus"}) {
+		t.Errorf("Expected romulus for rmls, got %v", keys)
+	}
+}
+
+// Every version of an immutable tree should stay as it was when it was made
+func TestImmutableVersions(t *testing.T) {
+
+	v0 := NewImmutableTree[string]()
+	v1, isNew := v0.Add("romane", "a")
+	if !isNew {
+		t.Errorf("Expected romane to be new")
+	}
+	v2, _ := v1.Add("romanus", "b")
+	v3, _ := v2.Add("romane", "c")
+	v4, removed := v3.Delete("romanus")
+	if !removed {
+		t.Errorf("Expected romanus to be removed")
+	}
+
+	if v5, removed := v4.Delete("rubens"); removed || v5 != v4 {
+		t.Errorf("Expected deleting a missing key to keep the version")
+	}
+
+	testCases := []struct {
+		Version  *ImmutableTree[string]
+		Expected []string
+		Content  []string
+	}{
+		{Version: v0, Expected: nil, Content: nil},
+		{Version: v1, Expected: []string{"romane"}, Content: []string{"a"}},
+		{
+			Version:  v2,
+			Expected: []string{"romane", "romanus"},
+			Content:  []string{"a", "b"},
+		},
+		{
+			Version:  v3,
+			Expected: []string{"romane", "romanus"},
+			Content:  []string{"c", "b"},
+		},
+		{Version: v4, Expected: []string{"romane"}, Content: []string{"c"}},
+	}
+
+	for i, test := range testCases {
+		keys, content := test.Version.PrefixSearch("rom")
+		if !slices.Equal(keys, test.Expected) {
+			t.Errorf("Expected version %d to hold %v, got %v",
+				i, test.Expected, keys)
+		}
+		if !slices.Equal(content, test.Content) {
+			t.Errorf("Expected version %d to have content %v, got %v",
+				i, test.Content, content)
+		}
+		if test.Version.Len() != len(test.Expected) {
+			t.Errorf("Expected version %d to have length %d, got %d",
+				i, len(test.Expected), test.Version.Len())
+		}
+	}
+
+	v6, count := v3.DeletePrefix("roma")
+	if count != 2 || v6.Len() != 0 || v3.Len() != 2 {
+		t.Errorf("Expected DeletePrefix to only empty the new version")
+	}
+}
+
+// Navigating between keys works from paths rather than parent links, so
+// should find the right neighbours in a tree sharing nodes with another
+func TestSnapshotNavigation(t *testing.T) {
+
+	r := NewTree[int]()
+	for i, key := range []string{"romane", "romanus", "romulus", "rubens"} {
+		r.Add(key, i)
+	}
+
+	frozen := r.Freeze()
+	r.Delete("romanus")
+	r.Add("rubicon", 4)
+
+	if key, _, _ := frozen.Next("romane"); key != "romanus" {
+		t.Errorf("Expected romanus after romane, got %s", key)
+	}
+	if key, _, _ := r.Next("romane"); key != "romulus" {
+		t.Errorf("Expected romulus after romane, got %s", key)
+	}
+	if key, _, _ := frozen.Max(); key != "rubens" {
+		t.Errorf("Expected rubens to be the largest, got %s", key)
+	}
+	if key, _, _ := r.Prev("rubicon"); key != "rubens" {
+		t.Errorf("Expected rubens before rubicon, got %s", key)
+	}
+	if key, _, _ := frozen.Select(1); key != "romanus" {
+		t.Errorf("Expected romanus at position 1, got %s", key)
+	}
+	if rank := frozen.Rank("rubicon"); rank != 4 {
+		t.Errorf("Expected a rank of 4, got %d", rank)
+	}
+}
+
+// Readers search the published version while a writer builds and publishes
+// new ones, run with -race to check the versions share nothing unsafely
+func TestImmutableConcurrentReaders(t *testing.T) {
+
+	writer := NewTree[interface{}]()
+	writer.Add("somerset road", identifier{"somerset road"})
+
+	var current atomic.Pointer[ImmutableRadixTree]
+	current.Store(writer.Freeze())
+
+	var wg sync.WaitGroup
+	var done atomic.Bool
+
+	for reader := 0; reader < 8; reader++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for !done.Load() {
+
+				version := current.Load()
+
+				keys, content := version.PrefixSearch("road")
+				compareKeysAndContent(keys, content, t)
+
+				// A version never changes, so its counts agree with a search
+				if count := version.CountPrefix("road"); count != len(keys) {
+					t.Errorf("Expected %d keys under road, got %d",
+						len(keys), count)
+				}
+
+				keys, content = version.FuzzySearch("som")
+				compareKeysAndContent(keys, content, t)
+			}
+		}()
+	}
+
+	for i := 0; i < 500; i++ {
+		key := fmt.Sprintf("road %d", i)
+		writer.Add(key, identifier{key})
+		if i%10 == 0 {
+			writer.Delete(fmt.Sprintf("road %d", i/2))
+		}
+		current.Store(writer.Freeze())
+	}
+
+	done.Store(true)
+	wg.Wait()
+
+	if current.Load().Len() != writer.Len() {
+		t.Errorf("Expected the last version to hold %d keys, got %d",
+			writer.Len(), current.Load().Len())
+	}
+}
@@ -0,0 +1,100 @@
+package radix
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// The form of a node in the structural dump, mirroring treeNode
+type jsonNode struct {
+	Key      string      `json:"key"`
+	BitMask  uint64      `json:"bitMask"`
+	Collect  bool        `json:"collect"`
+	Content  interface{} `json:"content,omitempty"`
+	Children []jsonNode  `json:"children,omitempty"`
+}
+
+// MarshalJSON encodes the tree as a flat object of keys to their content,
+// in key order
+func (tree *Tree[V]) MarshalJSON() ([]byte, error) {
+
+	var buf bytes.Buffer
+	var err error
+
+	buf.WriteByte('{')
+	tree.walk(tree.root, []byte{}, func(key []byte, node *treeNode[V]) bool {
+
+		var encodedKey, encodedContent []byte
+		if encodedKey, err = json.Marshal(node.originalKey(key)); err != nil {
+			return false
+		}
+		if encodedContent, err = json.Marshal(node.Content()); err != nil {
+			return false
+		}
+
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		buf.Write(encodedKey)
+		buf.WriteByte(':')
+		buf.Write(encodedContent)
+		return true
+	})
+	buf.WriteByte('}')
+
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON replaces the contents of the tree with the keys of a flat
+// object, as written by MarshalJSON. The tree is left as it was if the JSON
+// can't be decoded
+func (tree *Tree[V]) UnmarshalJSON(data []byte) error {
+
+	var flat map[string]V
+	if err := json.Unmarshal(data, &flat); err != nil {
+		return err
+	}
+
+	loaded := newTree[V](tree.options)
+	for key, content := range flat {
+		loaded.Add(key, content)
+	}
+
+	tree.root = loaded.root
+	tree.stringCount = loaded.stringCount
+	tree.nodeCount = loaded.nodeCount
+	tree.generation = loaded.generation
+
+	return nil
+}
+
+// StructureJSON dumps the nodes of the tree as indented JSON, each with its
+// key fragment, bit mask, whether it was inserted, its content and its
+// children. It is for looking at how the tree is built, it can't be loaded
+func (tree *Tree[V]) StructureJSON() ([]byte, error) {
+	return json.MarshalIndent(tree.jsonNode(tree.root), "", "  ")
+}
+
+// Builds the structural dump of a node and everything below it
+func (tree *Tree[V]) jsonNode(node *treeNode[V]) jsonNode {
+
+	dumped := jsonNode{
+		Key:     string(node.Key()),
+		BitMask: node.BitMask(),
+		Collect: node.Collect(),
+	}
+
+	if node.Collect() {
+		dumped.Content = node.Content()
+	}
+
+	for _, child := range node.Children() {
+		dumped.Children = append(dumped.Children, tree.jsonNode(child))
+	}
+
+	return dumped
+}
@@ -0,0 +1,99 @@
+package radix
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+// The flat form should be an object of keys in order, which loads back into
+// the same tree
+func TestJSONFlat(t *testing.T) {
+
+	r := NewTree[int]()
+	r.Add("romulus", 3)
+	r.Add("romane", 1)
+	r.Add("tesorería", 4)
+	r.Add("romanus", 2)
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		t.Fatalf("Expected to marshal the tree, got %s", err)
+	}
+
+	expected := `{"romane":1,"romanus":2,"romulus":3,"tesorería":4}`
+	if string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+
+	loaded := NewTree[int]()
+	loaded.Add("leftover", 5)
+	if err := json.Unmarshal(data, loaded); err != nil {
+		t.Fatalf("Expected to unmarshal the tree, got %s", err)
+	}
+
+	if loaded.String() != r.String() {
+		t.Errorf("Expected the same structure:\n%s\ngot:\n%s",
+			r.String(), loaded.String())
+	}
+
+	keys, content := loaded.PrefixSearch("")
+	if !slices.Equal(keys, []string{"romane", "romanus", "romulus", "tesorería"}) {
+		t.Errorf("Expected the loaded keys, got %v", keys)
+	}
+	if !slices.Equal(content, []int{1, 2, 3, 4}) {
+		t.Errorf("Expected the loaded content, got %v", content)
+	}
+
+	if err := json.Unmarshal([]byte(`{"romane":"one"}`), loaded); err == nil {
+		t.Errorf("Expected content of the wrong type to fail")
+	}
+	if loaded.Len() != 4 {
+		t.Errorf("Expected the tree to be unchanged after an error")
+	}
+
+	empty, _ := json.Marshal(NewTree[int]())
+	if string(empty) != "{}" {
+		t.Errorf("Expected an empty tree to give {}, got %s", empty)
+	}
+}
+
+// The structural form should mirror the nodes
+func TestJSONStructure(t *testing.T) {
+
+	r := NewTree[int]()
+	r.Add("romane", 1)
+	r.Add("romanus", 2)
+
+	data, err := r.StructureJSON()
+	if err != nil {
+		t.Fatalf("Expected to dump the tree, got %s", err)
+	}
+
+	var dumped jsonNode
+	if err := json.Unmarshal(data, &dumped); err != nil {
+		t.Fatalf("Expected the dump to be valid JSON, got %s", err)
+	}
+
+	if dumped.Key != "" || len(dumped.Children) != 1 {
+		t.Fatalf("Expected a root with one child, got %s", data)
+	}
+
+	roman := dumped.Children[0]
+	if roman.Key != "roman" || roman.Collect || roman.Content != nil {
+		t.Errorf("Expected roman not to be collected, got %+v", roman)
+	}
+	if roman.BitMask != genBitMask32([]byte("romaneus")) {
+		t.Errorf("Expected the bit mask of roman to cover its children")
+	}
+
+	if len(roman.Children) != 2 {
+		t.Fatalf("Expected roman to have two children, got %s", data)
+	}
+	if e := roman.Children[0]; e.Key != "e" || !e.Collect || e.Content != 1.0 {
+		t.Errorf("Expected e to be collected with content 1, got %+v", e)
+	}
+	if us := roman.Children[1]; us.Key != "us" || len(us.Children) != 0 {
+		t.Errorf("Expected us to be a leaf, got %+v", us)
+	}
+}
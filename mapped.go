@@ -0,0 +1,647 @@
+package radix
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"math"
+	"os"
+	"sort"
+)
+
+// The mapped format is a flat layout which can be searched where it lies,
+// without building any nodes. After the header come four sections:
+//
+//   - the nodes, a fixed size record each. Nodes are laid out breadth first
+//     so the children of a node sit next to each other, in key order
+//   - the content table, an offset and length into the values for each key,
+//     followed by the same for the key as it was inserted. The length is 0
+//     unless normalising changed the key
+//   - the key fragments of every node packed together
+//   - the encoded content and inserted key of every key packed together
+//
+// A CRC-32 of everything before it comes last. All numbers are little endian uint32s, except for the bit masks which are
+// uint64s
+const (
+	mappedMagic      = "RDXM"
+	mappedVersion    = 1
+	mappedHeaderSize = 32
+
+	// Key offset, key length, bit mask, first child, number of children
+	// and content index
+	mappedNodeSize = 28
+
+	// Content offset and length, then inserted key offset and length
+	mappedContentSize = 16
+
+	// The content index of a node which isn't a key
+	mappedNoContent = math.MaxUint32
+
+	// The CRC-32 at the end
+	mappedChecksumSize = 4
+)
+
+// ErrTooLarge is returned when a tree is too large for the mapped format
+var ErrTooLarge = errors.New("Tree is too large for the mapped format")
+
+// MappedTree is a read-only tree served straight from a file written by
+// WriteMapped. The file is memory mapped where the platform allows, and no
+// nodes are built from it, so opening it only costs a pass over the file to
+// check it. Content is decoded as it is returned
+type MappedTree[V any] struct {
+	data  []byte
+	unmap func([]byte) error
+
+	nodes    []byte
+	contents []byte
+	keys     []byte
+	values   []byte
+
+	numNodes int
+	numKeys  int
+
+	codec   Codec[V]
+	options treeOptions
+}
+
+// MappedRadixTree is a MappedTree which can hold content of any type
+type MappedRadixTree = MappedTree[interface{}]
+
+// A node as read from the node section
+type mappedNode struct {
+	key         []byte
+	bitMask     uint64
+	firstChild  int
+	numChildren int
+	content     uint32
+}
+
+// WriteMapped writes the tree out in the mapped format, to be opened with
+// OpenMapped. Content is encoded with the tree's codec
+func (tree *Tree[V]) WriteMapped(w io.Writer) (int64, error) {
+
+	// Order the nodes breadth first, noting where each one's children start
+	order := []*treeNode[V]{tree.root}
+	firstChild := []int{}
+	for i := 0; i < len(order); i++ {
+		firstChild = append(firstChild, len(order))
+		order = append(order, order[i].Children()...)
+	}
+
+	nodes := make([]byte, 0, len(order)*mappedNodeSize)
+	contents := make([]byte, 0, tree.stringCount*mappedContentSize)
+	keys := []byte{}
+	values := []byte{}
+	numKeys := 0
+
+	for i, node := range order {
+
+		content := uint32(mappedNoContent)
+		if node.Collect() {
+			encoded, err := tree.contentCodec().Encode(node.Content())
+			if err != nil {
+				return 0, err
+			}
+
+			contents = binary.LittleEndian.AppendUint32(contents, uint32(len(values)))
+			contents = binary.LittleEndian.AppendUint32(contents, uint32(len(encoded)))
+			values = append(values, encoded...)
+
+			contents = binary.LittleEndian.AppendUint32(contents, uint32(len(values)))
+			contents = binary.LittleEndian.AppendUint32(contents, uint32(len(node.original)))
+			values = append(values, node.original...)
+
+			content = uint32(numKeys)
+			numKeys++
+		}
+
+		nodes = binary.LittleEndian.AppendUint32(nodes, uint32(len(keys)))
+		nodes = binary.LittleEndian.AppendUint32(nodes, uint32(len(node.Key())))
+		nodes = binary.LittleEndian.AppendUint64(nodes, node.BitMask())
+		nodes = binary.LittleEndian.AppendUint32(nodes, uint32(firstChild[i]))
+		nodes = binary.LittleEndian.AppendUint32(nodes, uint32(len(node.Children())))
+		nodes = binary.LittleEndian.AppendUint32(nodes, content)
+
+		keys = append(keys, node.Key()...)
+	}
+
+	// Every offset must fit, along with the file as a whole
+	total := mappedHeaderSize + len(nodes) + len(contents) + len(keys) +
+		len(values) + mappedChecksumSize
+	if int64(total) > math.MaxUint32 {
+		return 0, ErrTooLarge
+	}
+
+	header := []byte(mappedMagic)
+	header = binary.LittleEndian.AppendUint32(header, mappedVersion)
+	header = binary.LittleEndian.AppendUint32(header, uint32(len(order)))
+	header = binary.LittleEndian.AppendUint32(header, uint32(numKeys))
+	header = binary.LittleEndian.AppendUint32(header, uint32(len(keys)))
+	header = binary.LittleEndian.AppendUint32(header, uint32(len(values)))
+	header = binary.LittleEndian.AppendUint32(header, uint32(tree.options.savedMode()))
+	header = binary.LittleEndian.AppendUint32(header, uint32(tree.options.normalisation))
+
+	hash := crc32.NewIEEE()
+	for _, section := range [][]byte{header, nodes, contents, keys, values} {
+		hash.Write(section)
+	}
+	checksum := binary.LittleEndian.AppendUint32(nil, hash.Sum32())
+
+	var written int64
+	for _, section := range [][]byte{header, nodes, contents, keys, values, checksum} {
+		n, err := w.Write(section)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+
+	return written, nil
+}
+
+// OpenMapped opens a file written by WriteMapped. Content is decoded with
+// gob unless another codec is set. The tree must be closed once finished
+// with, and nothing returned from it is valid afterwards. The file is
+// checked against its checksum and for nodes which point outside of it or
+// back up the tree, either of which is an error.
+//
+// The masks can't be rebuilt, so the options only matter when the tree was
+// written with a CharClassMapper, which must be given again. Without it the
+// fuzzy search still works but can't skip any branches
+func OpenMapped[V any](path string, opts ...Option) (*MappedTree[V], error) {
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	if info.Size() < mappedHeaderSize+mappedChecksumSize || info.Size() > math.MaxUint32 {
+		return nil, ErrInvalidFormat
+	}
+
+	data, unmap, err := mapFile(f, int(info.Size()))
+	if err != nil {
+		return nil, err
+	}
+
+	mt, err := newMappedTree[V](data, opts...)
+	if err != nil {
+		unmap(data)
+		return nil, err
+	}
+
+	mt.unmap = unmap
+	return mt, nil
+}
+
+// OpenMappedRadixTree opens a file written by WriteMapped from a RadixTree
+func OpenMappedRadixTree(path string, opts ...Option) (*MappedRadixTree, error) {
+	return OpenMapped[interface{}](path, opts...)
+}
+
+// Checks the header and splits the data into its sections
+func newMappedTree[V any](data []byte, opts ...Option) (*MappedTree[V], error) {
+
+	if len(data) < mappedHeaderSize+mappedChecksumSize ||
+		string(data[:len(mappedMagic)]) != mappedMagic {
+		return nil, ErrInvalidFormat
+	}
+
+	header := data[len(mappedMagic):mappedHeaderSize]
+	if binary.LittleEndian.Uint32(header[0:]) != mappedVersion {
+		return nil, ErrUnsupportedVersion
+	}
+
+	numNodes := int(binary.LittleEndian.Uint32(header[4:]))
+	numKeys := int(binary.LittleEndian.Uint32(header[8:]))
+	keyBytes := int(binary.LittleEndian.Uint32(header[12:]))
+	valueBytes := int(binary.LittleEndian.Uint32(header[16:]))
+	options := treeOptions{
+		maskMode:      MaskMode(binary.LittleEndian.Uint32(header[20:])),
+		normalisation: Normalisation(binary.LittleEndian.Uint32(header[24:])),
+	}
+	if options.maskMode == maskCustom {
+		options.mapper = newOptions(opts...).mapper
+	}
+
+	sizes := []int{
+		numNodes * mappedNodeSize,
+		numKeys * mappedContentSize,
+		keyBytes,
+		valueBytes,
+	}
+
+	total := mappedHeaderSize + mappedChecksumSize
+	for _, size := range sizes {
+		total += size
+	}
+
+	// There is always a root
+	if numNodes == 0 || total != len(data) {
+		return nil, ErrInvalidFormat
+	}
+
+	checksum := binary.LittleEndian.Uint32(data[len(data)-mappedChecksumSize:])
+	if crc32.ChecksumIEEE(data[:len(data)-mappedChecksumSize]) != checksum {
+		return nil, ErrChecksumMismatch
+	}
+
+	sections := make([][]byte, len(sizes))
+	offset := mappedHeaderSize
+	for i, size := range sizes {
+		sections[i] = data[offset : offset+size]
+		offset += size
+	}
+
+	mt := &MappedTree[V]{
+		data:     data,
+		nodes:    sections[0],
+		contents: sections[1],
+		keys:     sections[2],
+		values:   sections[3],
+		numNodes: numNodes,
+		numKeys:  numKeys,
+		options:  options,
+	}
+
+	if !mt.valid() {
+		return nil, ErrInvalidFormat
+	}
+
+	return mt, nil
+}
+
+// Checks that everything the nodes and content records point to lies within
+// their sections. The layout is breadth first, so the children of a node
+// always come after it, which means no walk down the tree can loop back on
+// itself
+func (mt *MappedTree[V]) valid() bool {
+
+	for i := 0; i < mt.numNodes; i++ {
+
+		record := mt.nodes[i*mappedNodeSize : (i+1)*mappedNodeSize]
+		keyOffset := int(binary.LittleEndian.Uint32(record[0:]))
+		keyLength := int(binary.LittleEndian.Uint32(record[4:]))
+		firstChild := int(binary.LittleEndian.Uint32(record[16:]))
+		numChildren := int(binary.LittleEndian.Uint32(record[20:]))
+		content := binary.LittleEndian.Uint32(record[24:])
+
+		if keyOffset+keyLength > len(mt.keys) {
+			return false
+		}
+		if numChildren > 0 && (firstChild <= i || firstChild+numChildren > mt.numNodes) {
+			return false
+		}
+		if content != mappedNoContent && int(content) >= mt.numKeys {
+			return false
+		}
+	}
+
+	for i := 0; i < mt.numKeys; i++ {
+
+		record := mt.contents[i*mappedContentSize : (i+1)*mappedContentSize]
+		for _, field := range []int{0, 8} {
+			offset := int(binary.LittleEndian.Uint32(record[field:]))
+			length := int(binary.LittleEndian.Uint32(record[field+4:]))
+			if offset+length > len(mt.values) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// SetCodec sets how content is decoded, it must match the codec the tree
+// was written with
+func (mt *MappedTree[V]) SetCodec(codec Codec[V]) {
+	mt.codec = codec
+}
+
+// Close unmaps the file
+func (mt *MappedTree[V]) Close() error {
+
+	data, unmap := mt.data, mt.unmap
+	*mt = MappedTree[V]{}
+
+	if data == nil || unmap == nil {
+		return nil
+	}
+
+	return unmap(data)
+}
+
+// Len returns the number of keys in the tree
+func (mt *MappedTree[V]) Len() int {
+	return mt.numKeys
+}
+
+// Get returns the content stored against an exact key
+func (mt *MappedTree[V]) Get(str string) (V, bool) {
+
+	input := mt.options.normalisation.normalise(str)
+	node := mt.node(0)
+	index := 0
+
+	for index < len(input) {
+		next, ok := mt.child(node, input[index:], false)
+		if !ok {
+			break
+		}
+		node = next
+		index += len(node.key)
+	}
+
+	if index != len(input) || len(input) == 0 || node.content == mappedNoContent {
+		var zero V
+		return zero, false
+	}
+
+	return mt.content(node), true
+}
+
+// PrefixSearch returns every key beginning with the prefix, in key order
+func (mt *MappedTree[V]) PrefixSearch(str string) ([]string, []V) {
+
+	keys := []string{}
+	content := []V{}
+
+	input := mt.options.normalisation.normalise(str)
+	node := mt.node(0)
+	found := []byte{}
+
+	for len(found) < len(input) {
+
+		// The prefix may end part way through the last node
+		next, ok := mt.child(node, input[len(found):], true)
+		if !ok {
+			return keys, content
+		}
+
+		node = next
+		found = append(found, node.key...)
+	}
+
+	mt.walk(node, found, func(key []byte, node mappedNode) bool {
+		keys = append(keys, mt.originalKey(key, node))
+		content = append(content, mt.content(node))
+		return true
+	})
+
+	return keys, content
+}
+
+// LongestPrefix returns the longest key that is a prefix of the string
+func (mt *MappedTree[V]) LongestPrefix(str string) (string, bool) {
+
+	input := mt.options.normalisation.normalise(str)
+	node := mt.node(0)
+	index := 0
+	longest := -1
+	var found mappedNode
+
+	for index < len(input) {
+
+		next, ok := mt.child(node, input[index:], false)
+		if !ok {
+			break
+		}
+
+		node = next
+		index += len(node.key)
+		if node.content != mappedNoContent {
+			longest = index
+			found = node
+		}
+	}
+
+	if longest < 0 {
+		return "", false
+	}
+
+	return mt.originalKey(input[:longest], found), true
+}
+
+// FuzzySearch returns every key containing the letters of the string in
+// order, the same as Tree.FuzzySearch
+func (mt *MappedTree[V]) FuzzySearch(str string) ([]string, []V) {
+
+	keys := []string{}
+	content := []V{}
+
+	if str == "" {
+		return keys, content
+	}
+
+	mt.fuzzySearch(
+		mt.options.normalisation.normalise(str),
+		mt.node(0),
+		0,
+		[]byte{},
+		func(key []byte, node mappedNode) bool {
+			keys = append(keys, mt.originalKey(key, node))
+			content = append(content, mt.content(node))
+			return true
+		})
+
+	return keys, content
+}
+
+// The fuzzy search of Tree, worked over the mapped nodes
+func (mt *MappedTree[V]) fuzzySearch(
+	str []byte,
+	node mappedNode,
+	index int,
+	found []byte,
+	fn func(key []byte, node mappedNode) bool,
+) bool {
+
+	// The string may have normalised to nothing, which matches nothing
+	if len(str) == 0 {
+		return true
+	}
+
+	searchBitMask := mt.options.masker()(str[index:])
+	startIndex := index
+
+	for i := 0; i < node.numChildren; i++ {
+
+		child := mt.node(node.firstChild + i)
+		index = startIndex
+
+		if !bitMaskContains(child.bitMask, searchBitMask) {
+			continue
+		}
+
+		for _, letter := range child.key {
+
+			// Part way through a multi-byte letter and it no longer
+			// matches, so start over on that letter
+			if letter != str[index] {
+				index = runeStartIndex(str, index)
+			}
+
+			if letter == str[index] {
+				index++
+			}
+
+			if index >= len(str) {
+				break
+			}
+		}
+
+		var more bool
+		if index >= len(str) {
+			more = mt.walk(child, append(found, child.key...), fn)
+		} else {
+			more = mt.fuzzySearch(str, child, index, append(found, child.key...), fn)
+		}
+
+		if !more {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Walks in order over every key from a node down
+func (mt *MappedTree[V]) walk(
+	node mappedNode,
+	prefix []byte,
+	fn func(key []byte, node mappedNode) bool,
+) bool {
+
+	if node.content != mappedNoContent && !fn(prefix, node) {
+		return false
+	}
+
+	for i := 0; i < node.numChildren; i++ {
+		child := mt.node(node.firstChild + i)
+		if !mt.walk(child, append(prefix, child.key...), fn) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Finds the child which the input continues into. The child's key must be
+// a prefix of the input, or if partial is set the input may instead end part
+// way through the child's key. Children are in order so a binary search
+// finds those starting with the right byte
+func (mt *MappedTree[V]) child(
+	node mappedNode,
+	input []byte,
+	partial bool,
+) (mappedNode, bool) {
+
+	start := sort.Search(node.numChildren, func(i int) bool {
+		key := mt.node(node.firstChild + i).key
+		return len(key) > 0 && key[0] >= input[0]
+	})
+
+	for i := start; i < node.numChildren; i++ {
+
+		child := mt.node(node.firstChild + i)
+		if len(child.key) == 0 || child.key[0] != input[0] {
+			break
+		}
+
+		if bytes.HasPrefix(input, child.key) ||
+			(partial && bytes.HasPrefix(child.key, input)) {
+			return child, true
+		}
+	}
+
+	return mappedNode{}, false
+}
+
+// Reads a node from the node section. The nodes were checked when the tree
+// was opened, but anything out of bounds (as in a closed tree) is read as an
+// empty node all the same
+func (mt *MappedTree[V]) node(index int) mappedNode {
+
+	if index < 0 || index >= mt.numNodes {
+		return mappedNode{content: mappedNoContent}
+	}
+
+	record := mt.nodes[index*mappedNodeSize : (index+1)*mappedNodeSize]
+	keyOffset := int(binary.LittleEndian.Uint32(record[0:]))
+	keyLength := int(binary.LittleEndian.Uint32(record[4:]))
+
+	node := mappedNode{
+		bitMask:     binary.LittleEndian.Uint64(record[8:]),
+		firstChild:  int(binary.LittleEndian.Uint32(record[16:])),
+		numChildren: int(binary.LittleEndian.Uint32(record[20:])),
+		content:     binary.LittleEndian.Uint32(record[24:]),
+	}
+
+	if keyOffset+keyLength <= len(mt.keys) {
+		node.key = mt.keys[keyOffset : keyOffset+keyLength]
+	}
+
+	if node.firstChild+node.numChildren > mt.numNodes {
+		node.numChildren = 0
+	}
+
+	return node
+}
+
+// Decodes the content of a node which is a key, the zero value is returned
+// if it can't be
+func (mt *MappedTree[V]) content(node mappedNode) V {
+
+	var zero V
+
+	index := int(node.content)
+	if node.content == mappedNoContent || index >= mt.numKeys {
+		return zero
+	}
+
+	record := mt.contents[index*mappedContentSize : (index+1)*mappedContentSize]
+	offset := int(binary.LittleEndian.Uint32(record[0:]))
+	length := int(binary.LittleEndian.Uint32(record[4:]))
+	if offset+length > len(mt.values) {
+		return zero
+	}
+
+	codec := mt.codec
+	if codec == nil {
+		codec = GobCodec[V]{}
+	}
+
+	content, err := codec.Decode(mt.values[offset : offset+length])
+	if err != nil {
+		return zero
+	}
+
+	return content
+}
+
+// Returns the key as it was inserted, the path to the node gives it as it's
+// stored
+func (mt *MappedTree[V]) originalKey(key []byte, node mappedNode) string {
+
+	index := int(node.content)
+	if node.content == mappedNoContent || index >= mt.numKeys {
+		return string(key)
+	}
+
+	record := mt.contents[index*mappedContentSize : (index+1)*mappedContentSize]
+	offset := int(binary.LittleEndian.Uint32(record[8:]))
+	length := int(binary.LittleEndian.Uint32(record[12:]))
+	if length == 0 || offset+length > len(mt.values) {
+		return string(key)
+	}
+
+	return string(mt.values[offset : offset+length])
+}
@@ -0,0 +1,204 @@
+package radix
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// Writes the tree out in the mapped format and opens it again
+func writeAndOpenMapped[V any](
+	tree *Tree[V],
+	t *testing.T,
+) *MappedTree[V] {
+
+	path := filepath.Join(t.TempDir(), "tree.mapped")
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Expected to create the file, got %s", err)
+	}
+
+	if _, err := tree.WriteMapped(f); err != nil {
+		t.Fatalf("Expected to write the tree, got %s", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("Expected to close the file, got %s", err)
+	}
+
+	mt, err := OpenMapped[V](path)
+	if err != nil {
+		t.Fatalf("Expected to open the tree, got %s", err)
+	}
+	t.Cleanup(func() { mt.Close() })
+
+	return mt
+}
+
+// Searches of the mapped tree should match those of the tree it came from
+func TestMappedSearches(t *testing.T) {
+
+	r := NewTree[int]()
+	for i, key := range []string{
+		"romane", "romanus", "romulus", "rubens", "ruber", "rubicon",
+		"rubicundus", "rom", "tesorería", "tesorero",
+	} {
+		r.Add(key, i)
+	}
+
+	mt := writeAndOpenMapped(r, t)
+
+	if mt.Len() != r.Len() {
+		t.Errorf("Expected %d keys, got %d", r.Len(), mt.Len())
+	}
+
+	for _, prefix := range []string{"", "r", "rom", "roma", "rub", "rubi", "tesorer", "x"} {
+		keys, content := r.PrefixSearch(prefix)
+		mappedKeys, mappedContent := mt.PrefixSearch(prefix)
+		if !slices.Equal(keys, mappedKeys) || !slices.Equal(content, mappedContent) {
+			t.Errorf("Expected prefix %s to give %v %v, got %v %v",
+				prefix, keys, content, mappedKeys, mappedContent)
+		}
+	}
+
+	for _, query := range []string{"rmn", "ubn", "rr", "ía", "o", "xyz"} {
+		keys, content := r.FuzzySearch(query)
+		mappedKeys, mappedContent := mt.FuzzySearch(query)
+		if !slices.Equal(keys, mappedKeys) || !slices.Equal(content, mappedContent) {
+			t.Errorf("Expected fuzzy %s to give %v %v, got %v %v",
+				query, keys, content, mappedKeys, mappedContent)
+		}
+	}
+
+	for _, str := range []string{"romanesque", "rom", "rubiconia", "ro", "tesorería s.a."} {
+		key, ok := r.LongestPrefix(str)
+		mappedKey, mappedOk := mt.LongestPrefix(str)
+		if key != mappedKey || ok != mappedOk {
+			t.Errorf("Expected longest prefix of %s to be %s, got %s",
+				str, key, mappedKey)
+		}
+	}
+
+	if value, ok := mt.Get("rubens"); !ok || value != 3 {
+		t.Errorf("Expected rubens to be 3, got %d", value)
+	}
+	if _, ok := mt.Get("roma"); ok {
+		t.Errorf("Expected roma not to be found")
+	}
+}
+
+// The integration tree should give the same results when mapped
+func TestMappedIntegrationTree(t *testing.T) {
+
+	r := buildIntegrationTree()
+	r.SetCodec(emptyCodec{})
+	defer r.SetCodec(nil)
+
+	mt := writeAndOpenMapped(r, t)
+	mt.SetCodec(emptyCodec{})
+
+	for _, query := range []string{"som", "smrst", "kingston"} {
+		keys, _ := r.FuzzySearch(query)
+		mappedKeys, _ := mt.FuzzySearch(query)
+		if !slices.Equal(keys, mappedKeys) {
+			t.Errorf("Expected the same fuzzy results for %s", query)
+		}
+	}
+
+	keys, _ := r.PrefixSearch("b")
+	mappedKeys, _ := mt.PrefixSearch("b")
+	if !slices.Equal(keys, mappedKeys) {
+		t.Errorf("Expected the same prefix results")
+	}
+}
+
+// Files which aren't in the mapped format are rejected
+func TestMappedErrors(t *testing.T) {
+
+	dir := t.TempDir()
+
+	if _, err := OpenMapped[int](filepath.Join(dir, "missing")); err == nil {
+		t.Errorf("Expected a missing file to fail")
+	}
+
+	r := NewTree[int]()
+	r.Add("romane", 1)
+	data, _ := r.MarshalBinary()
+
+	path := filepath.Join(dir, "binary")
+	os.WriteFile(path, data, 0o644)
+
+	if _, err := OpenMapped[int](path); err != ErrInvalidFormat {
+		t.Errorf("Expected the binary format to be rejected, got %v", err)
+	}
+
+	r.Add("romanus", 2)
+
+	var buf bytes.Buffer
+	r.WriteMapped(&buf)
+	mapped := buf.Bytes()
+
+	// Corrupts a copy of the file, making the checksum match again if asked
+	corrupt := func(fn func(data []byte), fixChecksum bool) []byte {
+
+		data := slices.Clone(mapped)
+		fn(data)
+
+		end := len(data) - mappedChecksumSize
+		if fixChecksum {
+			binary.LittleEndian.PutUint32(data[end:], crc32.ChecksumIEEE(data[:end]))
+		}
+
+		return data
+	}
+
+	testCases := []struct {
+		Data     []byte
+		Expected error
+	}{
+		{
+			// A byte of the content changed
+			Data:     corrupt(func(data []byte) { data[len(data)-5] ^= 0xff }, false),
+			Expected: ErrChecksumMismatch,
+		},
+		{
+			// The root as its own child would recurse for ever
+			Data: corrupt(func(data []byte) {
+				binary.LittleEndian.PutUint32(data[mappedHeaderSize+16:], 0)
+			}, true),
+			Expected: ErrInvalidFormat,
+		},
+		{
+			// Children past the last node
+			Data: corrupt(func(data []byte) {
+				binary.LittleEndian.PutUint32(data[mappedHeaderSize+20:], 100)
+			}, true),
+			Expected: ErrInvalidFormat,
+		},
+		{
+			// A key past the end of the key section
+			Data: corrupt(func(data []byte) {
+				binary.LittleEndian.PutUint32(data[mappedHeaderSize+mappedNodeSize:], 100)
+			}, true),
+			Expected: ErrInvalidFormat,
+		},
+	}
+
+	path = filepath.Join(dir, "corrupt")
+	for i, test := range testCases {
+		os.WriteFile(path, test.Data, 0o644)
+		if _, err := OpenMapped[int](path); err != test.Expected {
+			t.Errorf("Expected %v for case %d, got %v", test.Expected, i, err)
+		}
+	}
+
+	mt := writeAndOpenMapped(r, t)
+	mt.Close()
+	if keys, _ := mt.PrefixSearch(""); len(keys) != 0 {
+		t.Errorf("Expected nothing to be found once closed, got %v", keys)
+	}
+}
@@ -0,0 +1,20 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package radix
+
+import (
+	"io"
+	"os"
+)
+
+// Memory mapping isn't available here, so the whole file is read in. The
+// function returned has nothing to release
+func mapFile(f *os.File, size int) ([]byte, func([]byte) error, error) {
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(f, data); err != nil {
+		return nil, nil, err
+	}
+
+	return data, func([]byte) error { return nil }, nil
+}
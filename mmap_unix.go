@@ -0,0 +1,26 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package radix
+
+import (
+	"os"
+	"syscall"
+)
+
+// Maps the file into memory read only, the mapping outlives the file being
+// closed. The function returned releases it
+func mapFile(f *os.File, size int) ([]byte, func([]byte) error, error) {
+
+	data, err := syscall.Mmap(
+		int(f.Fd()),
+		0,
+		size,
+		syscall.PROT_READ,
+		syscall.MAP_SHARED)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return data, syscall.Munmap, nil
+}
@@ -0,0 +1,75 @@
+package radix
+
+// MultiTree keeps every piece of content that is added against a key,
+// rather than the last one winning. Underneath it is a Tree where the
+// content of each key is a slice of everything added to it
+type MultiTree[V any] struct {
+	tree *Tree[[]V]
+}
+
+// MultiRadixTree is a MultiTree which can hold content of any type
+type MultiRadixTree = MultiTree[interface{}]
+
+// NewMultiTree sets up and returns a MultiTree struct for content of type V
+func NewMultiTree[V any](opts ...Option) *MultiTree[V] {
+	return &MultiTree[V]{
+		tree: NewTree[[]V](opts...),
+	}
+}
+
+// NewMultiRadixTree sets up and returns a MultiRadixTree struct
+func NewMultiRadixTree(opts ...Option) *MultiRadixTree {
+	return NewMultiTree[interface{}](opts...)
+}
+
+// Add appends the content to those already held against the key. Returns
+// whether the key was new
+func (mt *MultiTree[V]) Add(str string, content V) bool {
+
+	if len(mt.tree.stringToBytes(str)) == 0 {
+		return false
+	}
+
+	isNew := false
+	mt.tree.Update(str, func(existing []V, exists bool) []V {
+		if !exists {
+			isNew = true
+		}
+		return append(existing, content)
+	})
+
+	return isNew
+}
+
+// Get returns everything which has been added against the key
+func (mt *MultiTree[V]) Get(str string) ([]V, bool) {
+	return mt.tree.Get(str)
+}
+
+// Contains checks whether the exact key has been added to the tree
+func (mt *MultiTree[V]) Contains(str string) bool {
+	return mt.tree.Contains(str)
+}
+
+// Delete removes a key along with all of its content
+func (mt *MultiTree[V]) Delete(str string) bool {
+	return mt.tree.Delete(str)
+}
+
+// Len returns the number of distinct keys, not the number of pieces of
+// content
+func (mt *MultiTree[V]) Len() int {
+	return mt.tree.Len()
+}
+
+// PrefixSearch works as it does on the Tree, with the content for each key
+// being everything added against it
+func (mt *MultiTree[V]) PrefixSearch(str string) ([]string, [][]V) {
+	return mt.tree.PrefixSearch(str)
+}
+
+// FuzzySearch works as it does on the Tree, with the content for each key
+// being everything added against it
+func (mt *MultiTree[V]) FuzzySearch(str string) ([]string, [][]V) {
+	return mt.tree.FuzzySearch(str)
+}
@@ -0,0 +1,44 @@
+package radix
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Content added against the same key should all be kept, in order
+func TestMultiAdd(t *testing.T) {
+
+	r := NewMultiRadixTree()
+
+	if !r.Add("rabbit", 1) {
+		t.Errorf("Expected rabbit to be new")
+	}
+	if !r.Add("rab", 2) {
+		t.Errorf("Expected rab to be new")
+	}
+	if r.Add("rabbit", 3) {
+		t.Errorf("Expected rabbit to already exist")
+	}
+
+	content, ok := r.Get("rabbit")
+	if !ok || !reflect.DeepEqual(content, []interface{}{1, 3}) {
+		t.Errorf("Expected rabbit to hold [1 3], got %+v", content)
+	}
+
+	if r.Len() != 2 {
+		t.Errorf("Expected 2 keys, got %d", r.Len())
+	}
+
+	keys, contents := r.PrefixSearch("rab")
+	expectedKeys := []string{"rab", "rabbit"}
+	expectedContent := [][]interface{}{{2}, {1, 3}}
+	if !reflect.DeepEqual(keys, expectedKeys) ||
+		!reflect.DeepEqual(contents, expectedContent) {
+		t.Errorf("Prefix result %+v %+v does not match expected %+v %+v",
+			keys, contents, expectedKeys, expectedContent)
+	}
+
+	if !r.Delete("rabbit") || r.Contains("rabbit") {
+		t.Errorf("Expected rabbit to be deleted")
+	}
+}
@@ -1,9 +1,14 @@
 package radix
 
-import "errors"
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"sort"
+)
 
-// The all-important building block
-type radixNode struct {
+// The all-important building block, holds content of type V
+type treeNode[V any] struct {
 
 	// The key is the set of bytes contained in the node
 	key []byte
@@ -12,94 +17,126 @@ type radixNode struct {
 	// squashed into 0-9
 	childbytes int32
 
-	// Contains a link up to the parent
-	parent *radixNode
-
 	// Child nodes
-	children []*radixNode
+	children []*treeNode[V]
 
 	// Content associated with this node
-	content interface{}
+	content V
 
 	// Is this something which was inserted?
 	doCollect bool
 
-	// The bit mask for all child letters (excluding itself)
-	bitMask uint32
+	// The key as it was inserted, only kept when the tree normalised it
+	// into something else
+	original string
+
+	// The bit mask for all child letters (excluding itself), only the low
+	// 32 bits are used unless the tree is in Mask64 mode
+	bitMask uint64
+
+	// The ordered pairs of letters in the keys at or below this node, only
+	// kept when the tree has bigrams on (see WithBigrams)
+	bigrams *bigramSignature
+
+	// The number of keys at or below this node
+	size int
+
+	// The generation of the tree which created the node, only that tree may
+	// change it. Others must copy it first (see writable)
+	generation uint64
 }
 
+// The node as used by the RadixTree
+type radixNode = treeNode[interface{}]
+
 // Returns the key run slice
-func (rn *radixNode) Key() []byte {
+func (rn *treeNode[V]) Key() []byte {
 	return rn.key
 }
 
-// Returns the pointer to the parent node
-func (rn *radixNode) Parent() *radixNode {
-	return rn.parent
-}
-
 // Returns the children contained within the parent node
-func (rn *radixNode) Children() []*radixNode {
+func (rn *treeNode[V]) Children() []*treeNode[V] {
 	return rn.children
 }
 
 // Sets the content of a radix node
-func (rn *radixNode) SetContent(content interface{}) {
+func (rn *treeNode[V]) SetContent(content V) {
 	rn.content = content
 }
 
-// Returns the content, this will need type inference when it comes out
-// to be of any use
-func (rn *radixNode) Content() interface{} {
+// Returns the content, for the RadixTree this will need type inference when
+// it comes out to be of any use
+func (rn *treeNode[V]) Content() V {
 	return rn.content
 }
 
-// OrBitMask will take a bit mask (uint32) and OR it (logical inclusive)
+// OrBitMask will take a bit mask (uint64) and OR it (logical inclusive)
 // to the current bit mask that is set.
-func (rn *radixNode) OrBitMask(bitMask uint32) {
+func (rn *treeNode[V]) OrBitMask(bitMask uint64) {
 	rn.bitMask |= bitMask
 }
 
 // IsBitMaskSet performs a check to see if the bit mask is set
-func (rn *radixNode) IsBitMaskSet(bitMask uint32) bool {
+func (rn *treeNode[V]) IsBitMaskSet(bitMask uint64) bool {
 	return bitMaskContains(rn.bitMask, bitMask)
 }
 
 // BitMask returns the bit mask which is set, should only have practical uses
 // in testing
-func (rn *radixNode) BitMask() uint32 {
+func (rn *treeNode[V]) BitMask() uint64 {
 	return rn.bitMask
 }
 
+// Size returns the number of keys at or below this node
+func (rn *treeNode[V]) Size() int {
+	return rn.size
+}
+
+// Adds to the number of keys held at or below each node along a path
+func addSize[V any](path []*treeNode[V], delta int) {
+	for _, node := range path {
+		node.size += delta
+	}
+}
+
 // Sets the node to be collected (this means it's a string that was
 // inserted)
-func (rn *radixNode) SetToCollect() {
+func (rn *treeNode[V]) SetToCollect() {
 	rn.doCollect = true
 }
 
 // Returns if this is a node which should be collected or not
-func (rn *radixNode) Collect() bool {
+func (rn *treeNode[V]) Collect() bool {
 	return rn.doCollect
 }
 
 // -----------------------------------------------------------------------------
 
-// Inserts a child node
-func (rn *radixNode) NewChild(key []byte) *radixNode {
+// Inserts a child node, the children are kept in order of their keys so
+// that walking the tree gives keys in lexicographic order. The masker is the
+// tree's, for generating the bit mask of the key
+func (rn *treeNode[V]) NewChild(key []byte, masker bitMasker) *treeNode[V] {
 
-	newNode := &radixNode{
+	newNode := &treeNode[V]{
 		key:        key,
 		childbytes: 0,
-		parent:     rn,
-		bitMask:    genBitMask(key),
+		bitMask:    masker(key),
+		generation: rn.generation,
 	}
-	rn.children = append(rn.children, newNode)
+
+	index := sort.Search(len(rn.children), func(i int) bool {
+		return bytes.Compare(rn.children[i].key, key) >= 0
+	})
+	rn.children = slices.Insert(rn.children, index, newNode)
 
 	return newNode
 }
 
 // Break will split a node into two nodes at a given index
-func (rn *radixNode) Break(index int) (*radixNode, error) {
+func (rn *treeNode[V]) Break(
+	index int,
+	masker bitMasker,
+) (*treeNode[V], error) {
 
 	if index > len(rn.Key()) {
 		return nil, errors.New("Index exceeds key length")
@@ -111,33 +148,163 @@ func (rn *radixNode) Break(index int) (*radixNode, error) {
 	content := rn.Content()
 	children := rn.Children()
 	collect := rn.Collect()
+	original := rn.original
 
 	// Set the vars, move children and add the child
 	rn.key = preKey
-	rn.content = nil
-	rn.children = make([]*radixNode, 0)
+	var zero V
+	rn.content = zero
+	rn.children = make([]*treeNode[V], 0)
 
-	child := rn.NewChild(sufKey)
+	child := rn.NewChild(sufKey, masker)
 	child.children = children
 	child.SetContent(content)
 
 	// Move the collects around (if need be)
 	rn.doCollect = false
 	child.doCollect = collect
+	rn.original = ""
+	child.original = original
+
+	// Both hold the same keys as before
+	child.size = rn.size
 
 	// Rebuild the child bit mask (contain itself and it's children)
-	child.OrBitMask(genBitMask(child.Key()))
+	child.OrBitMask(masker(child.Key()))
 	for _, childsChild := range child.Children() {
 		child.OrBitMask(childsChild.BitMask())
 	}
 
 	// Generate a bitmask on the parent, should have it's child's bytes
 	// set too
-	rn.OrBitMask(genBitMask(sufKey))
+	rn.OrBitMask(masker(sufKey))
+
+	// The signature is out of date, the child has none yet so is given one
+	// when the parent's is rebuilt (see RebuildBigrams)
+	rn.bigrams = nil
 
 	return rn, nil
 }
 
+// ChildrenStartingWith returns the children whose keys begin with the byte,
+// found with a binary search. Nodes are only split between whole letters, so
+// letters sharing a first byte mean there can be more than one
+func (rn *treeNode[V]) ChildrenStartingWith(b byte) []*treeNode[V] {
+	start, end := rn.childRange(b)
+	return rn.children[start:end]
+}
+
+// Returns the start and end index of the children beginning with the byte
+func (rn *treeNode[V]) childRange(b byte) (int, int) {
+
+	start := sort.Search(len(rn.children), func(i int) bool {
+		return rn.children[i].key[0] >= b
+	})
+
+	end := start
+	for end < len(rn.children) && rn.children[end].key[0] == b {
+		end++
+	}
+
+	return start, end
+}
+
+// Returns where the child sits amongst the children, or -1 if it isn't one
+func (rn *treeNode[V]) childIndex(child *treeNode[V]) int {
+
+	index := sort.Search(len(rn.children), func(i int) bool {
+		return bytes.Compare(rn.children[i].key, child.key) >= 0
+	})
+
+	if index < len(rn.children) && rn.children[index] == child {
+		return index
+	}
+
+	return -1
+}
+
+// Returns a node which the generation is allowed to change. If the node
+// belongs to another generation it is shared with a snapshot, so a copy is
+// made (the key bytes are never changed in place so can still be shared)
+func (rn *treeNode[V]) writable(generation uint64) *treeNode[V] {
+
+	if rn.generation == generation {
+		return rn
+	}
+
+	copied := *rn
+	copied.children = slices.Clone(rn.children)
+	copied.generation = generation
+
+	return &copied
+}
+
+// RemoveChild detaches the given child from this node, returning false if
+// it was not one of our children
+func (rn *treeNode[V]) RemoveChild(child *treeNode[V]) bool {
+
+	index := rn.childIndex(child)
+	if index < 0 {
+		return false
+	}
+
+	rn.children = slices.Delete(rn.children, index, index+1)
+	return true
+}
+
+// Merge is the inverse of Break, it folds a node's only child back into
+// itself so that the key, content and children of the child now belong to
+// this node
+func (rn *treeNode[V]) Merge(masker bitMasker) error {
+
+	if len(rn.Children()) != 1 {
+		return errors.New("Can only merge a node with a single child")
+	}
+
+	child := rn.Children()[0]
+
+	// Build a fresh key, the prefix may share a backing array with the
+	// suffix (see Break) so appending in place is not safe
+	key := make([]byte, 0, len(rn.Key())+len(child.Key()))
+	key = append(key, rn.Key()...)
+	key = append(key, child.Key()...)
+
+	rn.key = key
+	rn.content = child.Content()
+	rn.doCollect = child.Collect()
+	rn.original = child.original
+	rn.size = child.Size()
+
+	// The child may be shared with a snapshot, so take a copy of its
+	// children rather than its slice
+	rn.children = slices.Clone(child.Children())
+
+	rn.RebuildBitMask(masker)
+
+	return nil
+}
+
+// RebuildBitMask regenerates the bit mask from the node's own key and the
+// masks of its children, used when things below have been taken away
+func (rn *treeNode[V]) RebuildBitMask(masker bitMasker) {
+
+	rn.bitMask = masker(rn.Key())
+	for _, child := range rn.Children() {
+		rn.OrBitMask(child.BitMask())
+	}
+}
+
+// Regenerates the bit masks of the node and everything below it, used when
+// they were generated in a different mask mode
+func (rn *treeNode[V]) rebuildBitMasks(masker bitMasker) {
+
+	for _, child := range rn.Children() {
+		child.rebuildBitMasks(masker)
+	}
+
+	rn.RebuildBitMask(masker)
+}
+
 type (
 	terminate  bool
 	walkerFunc func([]byte, int, bool, bool, int) terminate
@@ -145,7 +312,7 @@ type (
 
 // WalkDepthFirst will execute a function for
 // each node visited depth first in the tree
-func (rn *radixNode) WalkDepthFirst(wf walkerFunc, depth int) {
+func (rn *treeNode[V]) WalkDepthFirst(wf walkerFunc, depth int) {
 
 	isFirst := true
 	numCurrentChildren := len(rn.Children())
@@ -174,3 +341,21 @@ func (rn *radixNode) WalkDepthFirst(wf walkerFunc, depth int) {
 		childNode.WalkDepthFirst(wf, depth+1)
 	}
 }
+
+// Counts the keys (collectable nodes) and the nodes contained within this
+// branch, including the node itself
+func (rn *treeNode[V]) count() (int, int) {
+
+	keys, nodes := 0, 1
+	if rn.Collect() {
+		keys++
+	}
+
+	for _, child := range rn.Children() {
+		childKeys, childNodes := child.count()
+		keys += childKeys
+		nodes += childNodes
+	}
+
+	return keys, nodes
+}
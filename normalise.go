@@ -0,0 +1,118 @@
+package radix
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Normalisation chooses how keys are made alike before they are stored, so
+// that keys differing only in these ways are one and the same. The strings
+// searched for are normalised the same way, while the keys returned are
+// those which were added. Flags can be combined, as in FoldCase|StripAccents
+type Normalisation int
+
+const (
+	// FoldCase treats upper and lower case letters as the same
+	FoldCase Normalisation = 1 << iota
+
+	// StripAccents treats accented letters as the letters they are based
+	// on, so "alarcon" finds "alarcón", "viet" finds "Việt" and "αθηνα"
+	// finds "Αθήνα". Letters of the Basic Multilingual Plane are decomposed
+	// as with NFKD and their combining marks dropped, and full width forms
+	// become their ASCII letter. Letters with no decomposition (such as ø,
+	// đ and ß) are left alone
+	StripAccents
+
+	// CollapseSpace turns each run of white space into a single space and
+	// drops any at the start and end
+	CollapseSpace
+)
+
+// WithNormalisation sets how the keys of the tree are normalised. Keys which
+// normalise to the same thing are the same key, adding one replaces the
+// other. Results are in order of the normalised keys, and the positions in a
+// FuzzyResult are within the normalised key
+func WithNormalisation(normalisation Normalisation) Option {
+	return func(options *treeOptions) {
+		options.normalisation = normalisation
+	}
+}
+
+// The table of letters with their accents dropped is generated
+//go:generate go run -tags generate gen_decompose.go
+
+// Returns the bytes of the string as normalised
+func (normalisation Normalisation) normalise(str string) []byte {
+
+	if normalisation == 0 {
+		return []byte(str)
+	}
+
+	normalised := make([]byte, 0, len(str))
+	space := false
+
+	for _, r := range str {
+
+		if normalisation&CollapseSpace != 0 && unicode.IsSpace(r) {
+			space = len(normalised) > 0
+			continue
+		}
+
+		bases := ""
+		if normalisation&StripAccents != 0 {
+			if unicode.Is(unicode.Mn, r) {
+				continue
+			}
+			bases = accentBases[r]
+			// Full width forms of the ASCII letters
+			if r >= 0xFF01 && r <= 0xFF5E {
+				r -= 0xFEE0
+			}
+		}
+
+		if space {
+			normalised = append(normalised, ' ')
+			space = false
+		}
+
+		if bases == "" {
+			normalised = normalisation.appendRune(normalised, r)
+			continue
+		}
+		for _, base := range bases {
+			normalised = normalisation.appendRune(normalised, base)
+		}
+	}
+
+	return normalised
+}
+
+// Appends the rune, in lower case when folding case
+func (normalisation Normalisation) appendRune(normalised []byte, r rune) []byte {
+
+	if normalisation&FoldCase != 0 {
+		r = unicode.ToLower(r)
+	}
+
+	return utf8.AppendRune(normalised, r)
+}
+
+// Returns the key as it was added, the path to the node gives it as it's
+// stored
+func (rn *treeNode[V]) originalKey(key []byte) string {
+
+	if rn.original != "" {
+		return rn.original
+	}
+
+	return string(key)
+}
+
+// Remembers the key as it was added, if normalising changed it
+func (rn *treeNode[V]) setOriginal(str string, key []byte) {
+
+	rn.original = ""
+	if string(key) != str {
+		rn.original = str
+	}
+}
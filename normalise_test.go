@@ -0,0 +1,265 @@
+package radix
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestNormalise(t *testing.T) {
+
+	testCases := []struct {
+		Normalisation Normalisation
+		Input         string
+		Expected      string
+	}{
+		{Normalisation: 0, Input: " Alarcón ", Expected: " Alarcón "},
+		{Normalisation: FoldCase, Input: "RoMa ÑANDÚ", Expected: "roma ñandú"},
+		{Normalisation: StripAccents, Input: "Alarcón ÑANDÚ", Expected: "Alarcon NANDU"},
+		{Normalisation: StripAccents, Input: "Alarcón", Expected: "Alarcon"},
+		{Normalisation: StripAccents, Input: "Ｒｏｍａ", Expected: "Roma"},
+		{Normalisation: StripAccents, Input: "øßđ", Expected: "øßđ"},
+		{Normalisation: StripAccents, Input: "București", Expected: "Bucuresti"},
+		{Normalisation: StripAccents, Input: "Việt Nam", Expected: "Viet Nam"},
+		{Normalisation: StripAccents, Input: "Αθήνα", Expected: "Αθηνα"},
+		{Normalisation: StripAccents, Input: "Йошкар-Ола", Expected: "Иошкар-Ола"},
+		{Normalisation: StripAccents, Input: "Ǆǅǆ", Expected: "DZDzdz"},
+		{Normalisation: FoldCase | StripAccents, Input: "Ǆǅ", Expected: "dzdz"},
+		{Normalisation: FoldCase | StripAccents, Input: "ΆΘΉΝΑ", Expected: "αθηνα"},
+		{Normalisation: StripAccents | CollapseSpace, Input: "a \u0301", Expected: "a"},
+		{Normalisation: CollapseSpace, Input: "  new \t\n york  ", Expected: "new york"},
+		{Normalisation: CollapseSpace, Input: " \t ", Expected: ""},
+		{
+			Normalisation: FoldCase | StripAccents | CollapseSpace,
+			Input:         " Calle  ALARCÓN, Madrid ",
+			Expected:      "calle alarcon, madrid",
+		},
+	}
+
+	for _, test := range testCases {
+		res := string(test.Normalisation.normalise(test.Input))
+		if res != test.Expected {
+			t.Errorf("Expected %q to normalise to %q, got %q",
+				test.Input, test.Expected, res)
+		}
+	}
+}
+
+// Normalises in every way there is
+var normaliseAll = WithNormalisation(FoldCase | StripAccents | CollapseSpace)
+
+// Builds a tree which normalises everything, with keys which need it
+func buildNormalisedTree() *Tree[int] {
+
+	r := NewTree[int](normaliseAll)
+
+	for i, key := range []string{
+		"Romane", "ROMANUS", "romulus", "Alarcón", "Alarconcillo",
+		"New  York", "Ávila",
+	} {
+		r.Add(key, i)
+	}
+
+	return r
+}
+
+// Searches should find keys however they were written, and return them as
+// they were added
+func TestNormalisedSearches(t *testing.T) {
+
+	r := buildNormalisedTree()
+
+	if keys, _ := r.PrefixSearch("ROM"); !slices.Equal(keys, []string{"Romane", "ROMANUS", "romulus"}) {
+		t.Errorf("Expected ROM to find every rom key, got %v", keys)
+	}
+	if keys, _ := r.FuzzySearch("alarcon"); !slices.Equal(keys, []string{"Alarcón", "Alarconcillo"}) {
+		t.Errorf("Expected alarcon to find alarcón, got %v", keys)
+	}
+	if keys, _ := r.FuzzySearch("  "); len(keys) != 0 {
+		t.Errorf("Expected white space to find nothing, got %v", keys)
+	}
+	if content, ok := r.Get("new york"); !ok || content != 5 {
+		t.Errorf("Expected new york to be found, got %d %t", content, ok)
+	}
+	if !r.Contains("AVILA") || r.Contains("avil") {
+		t.Errorf("Expected only AVILA to be contained")
+	}
+	if key, ok := r.LongestPrefix("alarcon street"); !ok || key != "Alarcón" {
+		t.Errorf("Expected the longest prefix to be Alarcón, got %s", key)
+	}
+	if key, _, ok := r.Min(); !ok || key != "Alarcón" {
+		t.Errorf("Expected the smallest key to be Alarcón, got %s", key)
+	}
+	if key, _, ok := r.Select(2); !ok || key != "Ávila" {
+		t.Errorf("Expected the third key to be Ávila, got %s", key)
+	}
+	if results := r.SearchWithinDistance("ROMANO", 1); len(results) != 1 ||
+		results[0].Key != "Romane" {
+		t.Errorf("Expected ROMANO to be within 1 of Romane, got %v", results)
+	}
+
+	var walked []string
+	r.Range("A", "NEW YORK", true, false, func(key string, _ int) bool {
+		walked = append(walked, key)
+		return true
+	})
+	if !slices.Equal(walked, []string{"Alarcón", "Alarconcillo", "Ávila"}) {
+		t.Errorf("Expected the range to hold the keys before new york, got %v", walked)
+	}
+}
+
+// Letters beyond Latin-1 and Latin Extended-A should lose their accents too
+func TestNormalisedSearchesOtherScripts(t *testing.T) {
+
+	r := NewTree[int](normaliseAll)
+
+	for i, key := range []string{"București", "Việt Nam", "Αθήνα", "Ærøskøbing"} {
+		r.Add(key, i)
+	}
+
+	testCases := []struct {
+		Search   string
+		Expected []string
+	}{
+		{Search: "bucuresti", Expected: []string{"București"}},
+		{Search: "viet", Expected: []string{"Việt Nam"}},
+		{Search: "αθηνα", Expected: []string{"Αθήνα"}},
+		{Search: "ΑΘΗΝΑ", Expected: []string{"Αθήνα"}},
+		{Search: "ærø", Expected: []string{"Ærøskøbing"}},
+	}
+
+	for _, test := range testCases {
+		if keys, _ := r.FuzzySearch(test.Search); !slices.Equal(keys, test.Expected) {
+			t.Errorf("Expected %q to find %v, got %v", test.Search, test.Expected, keys)
+		}
+	}
+}
+
+// Keys which normalise to the same thing are the same key
+func TestNormalisedChanges(t *testing.T) {
+
+	r := buildNormalisedTree()
+
+	if _, isNew := r.Add("ROMANE", 10); isNew {
+		t.Errorf("Expected ROMANE to replace Romane")
+	}
+	if _, isNew := r.Add("   ", 11); isNew || r.Len() != 7 {
+		t.Errorf("Expected white space not to be added, got %d keys", r.Len())
+	}
+	if keys, content := r.PrefixSearch("romane"); !slices.Equal(keys, []string{"ROMANE"}) ||
+		content[0] != 10 {
+		t.Errorf("Expected ROMANE to be returned, got %v %v", keys, content)
+	}
+
+	// Splitting and merging nodes should keep the keys as added
+	r.Add("alarco", 12)
+	r.Delete("ALARCON")
+	if keys, _ := r.PrefixSearch("alarc"); !slices.Equal(keys, []string{"alarco", "Alarconcillo"}) {
+		t.Errorf("Expected the alarc keys to be kept, got %v", keys)
+	}
+	r.Delete("alarco")
+	if keys, _ := r.PrefixSearch("alarc"); !slices.Equal(keys, []string{"Alarconcillo"}) {
+		t.Errorf("Expected the merged key to be kept, got %v", keys)
+	}
+
+	if removed := r.DeletePrefix("ROMAN"); removed != 2 {
+		t.Errorf("Expected 2 keys to be removed, got %d", removed)
+	}
+	if removed := r.DeletePrefix(" "); removed != 4 || r.Len() != 0 {
+		t.Errorf("Expected white space to empty the tree, got %d", removed)
+	}
+}
+
+// Bulk loading should order and remember the keys the same way
+func TestNormalisedBuild(t *testing.T) {
+
+	r := buildNormalisedTree()
+	keys, content := r.PrefixSearch("")
+
+	built, err := BuildFromSorted(pairs(keys, content), normaliseAll)
+	if err != nil {
+		t.Fatalf("Expected to build the tree, got %s", err)
+	}
+	compareNodes(r.root, built.root, "", t)
+
+	slices.Reverse(keys)
+	slices.Reverse(content)
+	unsorted := BuildFromUnsorted(pairs(keys, content), normaliseAll)
+	compareNodes(r.root, unsorted.root, "", t)
+
+	if _, err := BuildFromSorted(pairs(keys, content), normaliseAll); err != ErrUnsorted {
+		t.Errorf("Expected the reversed keys to be unsorted, got %v", err)
+	}
+}
+
+// Saving the tree should keep the keys as added, and reading it into a tree
+// which normalises differently should normalise them again
+func TestNormalisedSaving(t *testing.T) {
+
+	r := buildNormalisedTree()
+	keys, _ := r.PrefixSearch("")
+
+	data, err := r.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Expected to write the tree, got %s", err)
+	}
+
+	loaded := NewTree[int](normaliseAll)
+	if err := loaded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("Expected to read the tree, got %s", err)
+	}
+	compareNodes(r.root, loaded.root, "", t)
+
+	plain := NewTree[int]()
+	if err := plain.UnmarshalBinary(data); err != nil {
+		t.Fatalf("Expected to read the tree, got %s", err)
+	}
+	if found, _ := plain.PrefixSearch("Rom"); !slices.Equal(found, []string{"Romane"}) {
+		t.Errorf("Expected the keys to be as added, got %v", found)
+	}
+	if plain.Len() != r.Len() {
+		t.Errorf("Expected %d keys, got %d", r.Len(), plain.Len())
+	}
+
+	encoded, err := r.MarshalJSON()
+	if err != nil {
+		t.Fatalf("Expected to encode the tree, got %s", err)
+	}
+	decoded := NewTree[int](normaliseAll)
+	if err := decoded.UnmarshalJSON(encoded); err != nil {
+		t.Fatalf("Expected to decode the tree, got %s", err)
+	}
+	if found, _ := decoded.PrefixSearch(""); !slices.Equal(found, keys) {
+		t.Errorf("Expected the decoded keys to be %v, got %v", keys, found)
+	}
+
+	var mapped bytes.Buffer
+	if _, err := r.WriteMapped(&mapped); err != nil {
+		t.Fatalf("Expected to write the tree, got %s", err)
+	}
+	path := filepath.Join(t.TempDir(), "tree.mapped")
+	if err := os.WriteFile(path, mapped.Bytes(), 0o644); err != nil {
+		t.Fatalf("Expected to save the tree, got %s", err)
+	}
+
+	mt, err := OpenMapped[int](path)
+	if err != nil {
+		t.Fatalf("Expected to open the tree, got %s", err)
+	}
+	defer mt.Close()
+
+	if found, _ := mt.PrefixSearch("ROM"); !slices.Equal(found, []string{"Romane", "ROMANUS", "romulus"}) {
+		t.Errorf("Expected ROM to find every rom key, got %v", found)
+	}
+	if found, _ := mt.FuzzySearch("ALARCON"); !slices.Equal(found, []string{"Alarcón", "Alarconcillo"}) {
+		t.Errorf("Expected ALARCON to find alarcón, got %v", found)
+	}
+	if content, ok := mt.Get(" new york "); !ok || content != 5 {
+		t.Errorf("Expected new york to be found, got %d %t", content, ok)
+	}
+	if key, ok := mt.LongestPrefix("ávila road"); !ok || key != "Ávila" {
+		t.Errorf("Expected the longest prefix to be Ávila, got %s", key)
+	}
+}
@@ -0,0 +1,76 @@
+package radix
+
+// MaskMode chooses how the letters held below each node are summed up in its
+// bit mask, which the fuzzy search checks to skip whole branches
+type MaskMode int
+
+const (
+	// Mask32 fits everything into 32 bits. Letters get a bit each, digits
+	// share a bit between two and everything else shares the last bit
+	Mask32 MaskMode = iota
+
+	// Mask64 uses 64 bits, giving each digit, the common punctuation and
+	// the most frequent accented letters a bit of their own. Searches for
+	// addresses, which are full of these, skip far more branches
+	Mask64
+)
+
+// Option changes how a tree is built, given when it is made
+type Option func(*treeOptions)
+
+// The settings a tree is made with
+type treeOptions struct {
+	maskMode MaskMode
+	mapper   CharClassMapper
+	bigrams  bool
+
+	normalisation Normalisation
+}
+
+// Applies the options in turn to the default settings
+func newOptions(opts ...Option) treeOptions {
+
+	var options treeOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return options
+}
+
+// WithMaskMode sets how the bit masks of the tree are generated
+func WithMaskMode(mode MaskMode) Option {
+	return func(options *treeOptions) {
+		options.maskMode = mode
+	}
+}
+
+// Returns the function generating the bit masks of the tree
+func (tree *Tree[V]) masker() bitMasker {
+	return tree.options.masker()
+}
+
+// Returns the function generating the bit masks for the settings
+func (options treeOptions) masker() bitMasker {
+
+	switch {
+	case options.mapper != nil:
+		return options.mapper.genBitMask
+	case options.maskMode == maskCustom:
+		return unknownBitMask
+	case options.maskMode == Mask64:
+		return genBitMask64
+	}
+
+	return genBitMask32
+}
+
+// Returns the mask mode the tree is saved with
+func (options treeOptions) savedMode() MaskMode {
+
+	if options.mapper != nil {
+		return maskCustom
+	}
+
+	return options.maskMode
+}
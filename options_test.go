@@ -0,0 +1,145 @@
+package radix
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+)
+
+// Counts the nodes whose bit mask holds every letter of the string, which are
+// the ones the fuzzy search can't skip
+func countMaskMatches[V any](tree *Tree[V], str string) int {
+
+	searchBitMask := tree.masker()([]byte(str))
+	count := 0
+
+	var visit func(node *treeNode[V])
+	visit = func(node *treeNode[V]) {
+		for _, child := range node.Children() {
+			if child.IsBitMaskSet(searchBitMask) {
+				count++
+				visit(child)
+			}
+		}
+	}
+	visit(tree.root)
+
+	return count
+}
+
+// Both mask modes should find the same keys, but the 64 bit masks should
+// rule out more of the tree for searches with punctuation in them
+func TestMaskModes(t *testing.T) {
+
+	r32 := buildIntegrationTree()
+	keys, content := r32.PrefixSearch("")
+	r64, err := BuildFromSorted(pairs(keys, content), WithMaskMode(Mask64))
+	if err != nil {
+		t.Fatalf("Expected to build the tree, got %s", err)
+	}
+
+	for _, search := range []string{"som", "somer", "road,", "rd, k", "st. j"} {
+
+		keys32, _ := r32.FuzzySearch(search)
+		keys64, _ := r64.FuzzySearch(search)
+		if !slices.Equal(keys32, keys64) {
+			t.Errorf("Expected %s to find the same %d keys in both modes, got %d",
+				search, len(keys32), len(keys64))
+		}
+	}
+
+	for _, search := range []string{"road,", "st. j", "king charles'"} {
+
+		matches32 := countMaskMatches(r32, search)
+		matches64 := countMaskMatches(r64, search)
+		if matches64 >= matches32 {
+			t.Errorf("Expected %s to match fewer nodes with 64 bit masks, got %d and %d",
+				search, matches64, matches32)
+		}
+	}
+}
+
+// Trees added to one at a time should be built the same whatever the mode
+func TestMask64Tree(t *testing.T) {
+
+	r := NewTree[int](WithMaskMode(Mask64))
+	keys := []string{
+		"calle 12", "calle 13", "calle 1", "plaza españa", "plaza mayor",
+		"avenida de américa, 4", "avenida de américa, 40",
+	}
+	for i, key := range keys {
+		r.Add(key, i)
+	}
+
+	expected := []struct {
+		Search string
+		Keys   []string
+	}{
+		{Search: "c12", Keys: []string{"calle 12"}},
+		{Search: "c1", Keys: []string{"calle 1", "calle 12", "calle 13"}},
+		{Search: "ña", Keys: []string{"plaza españa"}},
+		{Search: "amé,0", Keys: []string{"avenida de américa, 40"}},
+		{Search: "9", Keys: []string{}},
+	}
+
+	for _, test := range expected {
+		found, _ := r.FuzzySearch(test.Search)
+		if !slices.Equal(found, test.Keys) {
+			t.Errorf("Expected %s to find %v, got %v", test.Search, test.Keys, found)
+		}
+	}
+
+	r.Delete("calle 12")
+	r.Delete("calle 13")
+	if mask := r.root.Children()[1].BitMask(); mask != genBitMask64([]byte("calle 1")) {
+		t.Errorf("Expected the merged node to have the mask %b, got %b",
+			genBitMask64([]byte("calle 1")), mask)
+	}
+}
+
+// Reading a tree saved in the other mode should rebuild its masks
+func TestMaskModeBinary(t *testing.T) {
+
+	r := NewTree[int](WithMaskMode(Mask64))
+	for i, key := range []string{"calle 12", "calle 13", "plaza españa"} {
+		r.Add(key, i)
+	}
+
+	var buf bytes.Buffer
+	if _, err := r.WriteTo(&buf); err != nil {
+		t.Fatalf("Expected to write the tree, got %s", err)
+	}
+
+	for _, mode := range []MaskMode{Mask32, Mask64} {
+
+		loaded := NewTree[int](WithMaskMode(mode))
+		if _, err := loaded.ReadFrom(bytes.NewReader(buf.Bytes())); err != nil {
+			t.Fatalf("Expected to read the tree, got %s", err)
+		}
+
+		rebuilt := NewTree[int](WithMaskMode(mode))
+		for i, key := range []string{"calle 12", "calle 13", "plaza españa"} {
+			rebuilt.Add(key, i)
+		}
+		compareNodes(rebuilt.root, loaded.root, "", t)
+	}
+}
+
+// The mapped tree should use the masks of the tree it was written from
+func TestMaskModeMapped(t *testing.T) {
+
+	r := NewTree[int](WithMaskMode(Mask64))
+	for i, key := range []string{"calle 12", "calle 13", "plaza españa"} {
+		r.Add(key, i)
+	}
+
+	mt := writeAndOpenMapped(r, t)
+
+	for _, search := range []string{"c12", "c1", "ña", "9"} {
+		keys, _ := r.FuzzySearch(search)
+		mappedKeys, _ := mt.FuzzySearch(search)
+		if !slices.Equal(keys, mappedKeys) {
+			t.Errorf("Expected %s to find %v, got %v", search, keys, mappedKeys)
+		}
+	}
+}
@@ -0,0 +1,459 @@
+package radix
+
+import (
+	"bytes"
+	"sort"
+)
+
+// Min returns the smallest key in the tree along with its content
+func (tree *Tree[V]) Min() (string, V, bool) {
+	return tree.result(tree.rootPath().first())
+}
+
+// Max returns the largest key in the tree along with its content
+func (tree *Tree[V]) Max() (string, V, bool) {
+	return tree.result(tree.rootPath().last())
+}
+
+// Floor returns the largest key which is less than or equal to the string
+func (tree *Tree[V]) Floor(str string) (string, V, bool) {
+	path, _ := tree.floor(tree.stringToBytes(str))
+	return tree.result(path)
+}
+
+// Ceiling returns the smallest key which is greater than or equal to the
+// string
+func (tree *Tree[V]) Ceiling(str string) (string, V, bool) {
+	path, _ := tree.ceiling(tree.stringToBytes(str))
+	return tree.result(path)
+}
+
+// Next returns the smallest key which is strictly greater than the string
+func (tree *Tree[V]) Next(str string) (string, V, bool) {
+
+	path, exact := tree.ceiling(tree.stringToBytes(str))
+	if exact {
+		path = path.successor()
+	}
+
+	return tree.result(path)
+}
+
+// Prev returns the largest key which is strictly less than the string
+func (tree *Tree[V]) Prev(str string) (string, V, bool) {
+
+	path, exact := tree.floor(tree.stringToBytes(str))
+	if exact {
+		path = path.predecessor()
+	}
+
+	return tree.result(path)
+}
+
+// Range calls the function, in key order, for every key between from and to.
+// Whether each end of the range is included is set by the flags. Only the
+// branches of the tree which overlap the range are descended into, returning
+// false from the function stops the walk
+func (tree *Tree[V]) Range(
+	from string,
+	to string,
+	fromInclusive bool,
+	toInclusive bool,
+	fn func(key string, value V) bool,
+) {
+
+	bounds := &keyRange{
+		from:          tree.stringToBytes(from),
+		to:            tree.stringToBytes(to),
+		fromInclusive: fromInclusive,
+		toInclusive:   toInclusive,
+	}
+
+	tree.walkRange(
+		tree.root,
+		[]byte{},
+		bounds,
+		true,
+		true,
+		func(key []byte, node *treeNode[V]) bool {
+			return fn(node.originalKey(key), node.Content())
+		})
+}
+
+// The bounds of a range walk
+type keyRange struct {
+	from          []byte
+	to            []byte
+	fromInclusive bool
+	toInclusive   bool
+}
+
+// Walks the keys at or below the node which fall within the range. The check
+// flags say whether keys here could fall outside of either end, once a branch
+// sits wholly inside the range it is handed to the normal walk
+func (tree *Tree[V]) walkRange(
+	node *treeNode[V],
+	prefix []byte,
+	bounds *keyRange,
+	checkFrom bool,
+	checkTo bool,
+	fn func(key []byte, node *treeNode[V]) bool,
+) bool {
+
+	if !checkFrom && !checkTo {
+		return tree.walk(node, prefix, fn)
+	}
+
+	if node.Collect() {
+
+		inRange := true
+		if checkFrom {
+			cmp := bytes.Compare(prefix, bounds.from)
+			inRange = cmp > 0 || (cmp == 0 && bounds.fromInclusive)
+		}
+
+		// Everything below extends this key, so once past the end we can
+		// stop altogether
+		if checkTo {
+			cmp := bytes.Compare(prefix, bounds.to)
+			if cmp > 0 || (cmp == 0 && !bounds.toInclusive) {
+				return false
+			}
+		}
+
+		if inRange && !fn(prefix, node) {
+			return false
+		}
+	}
+
+	for _, child := range node.Children() {
+
+		childPrefix := append(prefix, child.Key()...)
+		childCheckFrom := checkFrom
+		childCheckTo := checkTo
+
+		if checkFrom {
+			switch comparePrefix(childPrefix, bounds.from) {
+			case -1:
+				// The whole branch comes before the range
+				continue
+			case 1:
+				childCheckFrom = false
+			}
+		}
+
+		if checkTo {
+			switch comparePrefix(childPrefix, bounds.to) {
+			case 1:
+				// This and every later branch comes after the range
+				return false
+			case -1:
+				childCheckTo = false
+			}
+		}
+
+		more := tree.walkRange(
+			child,
+			childPrefix,
+			bounds,
+			childCheckFrom,
+			childCheckTo,
+			fn)
+
+		if !more {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Compares every key beginning with the prefix against a bound. Returns -1
+// if they all come before it, 1 if they all come after it and 0 if the
+// bound itself begins with the prefix so they can fall either side
+func comparePrefix(prefix []byte, bound []byte) int {
+
+	if len(prefix) > len(bound) {
+		if bytes.HasPrefix(prefix, bound) {
+			return 1
+		}
+		return bytes.Compare(prefix[:len(bound)], bound)
+	}
+
+	return bytes.Compare(prefix, bound[:len(prefix)])
+}
+
+// Rank returns the number of keys which are less than the string, the
+// counts kept on each node mean whole branches are counted at once
+func (tree *Tree[V]) Rank(str string) int {
+
+	input := tree.stringToBytes(str)
+	node := tree.root
+	index := 0
+	rank := 0
+
+	for index < len(input) {
+
+		rest := input[index:]
+		children := node.Children()
+
+		i := sort.Search(len(children), func(i int) bool {
+			return bytes.Compare(children[i].Key(), rest) > 0
+		})
+
+		// Every branch before the one we may descend into is smaller
+		for _, child := range children[:max(i-1, 0)] {
+			rank += child.Size()
+		}
+
+		if i == 0 {
+			break
+		}
+
+		child := children[i-1]
+		if !bytes.HasPrefix(rest, child.Key()) {
+			rank += child.Size()
+			break
+		}
+
+		// A key which is a prefix of the string is smaller than it
+		node = child
+		index += len(child.Key())
+		if node.Collect() && index < len(input) {
+			rank++
+		}
+	}
+
+	return rank
+}
+
+// Select returns the key at the position (from 0) in key order, it is the
+// opposite of Rank
+func (tree *Tree[V]) Select(position int) (string, V, bool) {
+
+	if position < 0 || position >= tree.root.Size() {
+		return tree.result(nil)
+	}
+
+	path := tree.rootPath()
+	for {
+
+		node := path.node()
+		if node.Collect() {
+			if position == 0 {
+				return tree.result(path)
+			}
+			position--
+		}
+
+		// Skip over whole branches until the one containing the position
+		for _, child := range node.Children() {
+			if position < child.Size() {
+				path = append(path, child)
+				break
+			}
+			position -= child.Size()
+		}
+	}
+}
+
+// Turns a path into the values returned by the navigation methods
+func (tree *Tree[V]) result(path nodePath[V]) (string, V, bool) {
+
+	if path == nil {
+		var zero V
+		return "", zero, false
+	}
+
+	return path.node().originalKey(path.key()), path.node().Content(), true
+}
+
+// A path holding just the root, for starting a descent
+func (tree *Tree[V]) rootPath() nodePath[V] {
+	return nodePath[V]{tree.root}
+}
+
+// Finds the smallest key greater than or equal to the input, and whether it
+// is the input itself
+func (tree *Tree[V]) ceiling(input []byte) (nodePath[V], bool) {
+
+	path, child, exact := tree.locate(input)
+
+	if exact {
+		if path.node().Collect() {
+			return path, true
+		}
+		return path.first(), false
+	}
+
+	if child != nil {
+		return append(path, child).first(), false
+	}
+
+	return path.after(), false
+}
+
+// Finds the largest key less than or equal to the input, and whether it is
+// the input itself
+func (tree *Tree[V]) floor(input []byte) (nodePath[V], bool) {
+
+	path, child, exact := tree.locate(input)
+
+	if exact {
+		if path.node().Collect() {
+			return path, true
+		}
+		return path.predecessor(), false
+	}
+
+	if child != nil {
+		return append(path, child).predecessor(), false
+	}
+
+	return path.last(), false
+}
+
+// Descends as far as the input allows to find where it sits in the tree. If
+// the input ends exactly at the end of a node then the path to that node is
+// returned and exact is true. Otherwise the path ends at the deepest node
+// which the input passes through, and the child is the first of its children
+// that comes after the input (nil if they all come before it)
+func (tree *Tree[V]) locate(input []byte) (nodePath[V], *treeNode[V], bool) {
+
+	path := tree.rootPath()
+	index := 0
+
+	for index < len(input) {
+
+		rest := input[index:]
+		children := path.node().Children()
+
+		// The first child after the input, the one before it is the only
+		// one that can be a prefix of the input
+		i := sort.Search(len(children), func(i int) bool {
+			return bytes.Compare(children[i].Key(), rest) > 0
+		})
+
+		if i > 0 && bytes.HasPrefix(rest, children[i-1].Key()) {
+			path = append(path, children[i-1])
+			index += len(children[i-1].Key())
+			continue
+		}
+
+		if i < len(children) {
+			return path, children[i], false
+		}
+
+		return path, nil, false
+	}
+
+	return path, nil, true
+}
+
+// -----------------------------------------------------------------------------
+
+// The nodes passed through from the root down to a node. Nodes don't keep a
+// link to their parent (they can be shared between snapshots, so may have
+// more than one), so stepping between neighbours works back up the path
+type nodePath[V any] []*treeNode[V]
+
+// The node at the end of the path
+func (np nodePath[V]) node() *treeNode[V] {
+	return np[len(np)-1]
+}
+
+// Returns the full key of the node at the end of the path
+func (np nodePath[V]) key() []byte {
+
+	length := 0
+	for _, node := range np {
+		length += len(node.Key())
+	}
+
+	key := make([]byte, 0, length)
+	for _, node := range np {
+		key = append(key, node.Key()...)
+	}
+
+	return key
+}
+
+// Extends the path to the smallest key at or below its node
+func (np nodePath[V]) first() nodePath[V] {
+
+	node := np.node()
+	for !node.Collect() {
+		if len(node.Children()) == 0 {
+			return nil
+		}
+		node = node.Children()[0]
+		np = append(np, node)
+	}
+
+	return np
+}
+
+// Extends the path to the largest key at or below its node
+func (np nodePath[V]) last() nodePath[V] {
+
+	node := np.node()
+	for len(node.Children()) > 0 {
+		node = node.Children()[len(node.Children())-1]
+		np = append(np, node)
+	}
+
+	if !node.Collect() {
+		return nil
+	}
+
+	return np
+}
+
+// The smallest key after everything at or below the node, found by working
+// back up the path until there is a sibling to the right
+func (np nodePath[V]) after() nodePath[V] {
+
+	for len(np) > 1 {
+
+		siblings := np[len(np)-2].Children()
+		if i := np[len(np)-2].childIndex(np.node()); i+1 < len(siblings) {
+			np[len(np)-1] = siblings[i+1]
+			return np.first()
+		}
+
+		np = np[:len(np)-1]
+	}
+
+	return nil
+}
+
+// The key immediately after the node
+func (np nodePath[V]) successor() nodePath[V] {
+
+	if children := np.node().Children(); len(children) > 0 {
+		return append(np, children[0]).first()
+	}
+
+	return np.after()
+}
+
+// The key immediately before the node, either the largest key of a sibling
+// to the left or a node above which is a key itself
+func (np nodePath[V]) predecessor() nodePath[V] {
+
+	for len(np) > 1 {
+
+		parent := np[len(np)-2]
+		if i := parent.childIndex(np.node()); i > 0 {
+			np[len(np)-1] = parent.Children()[i-1]
+			return np.last()
+		}
+
+		np = np[:len(np)-1]
+		if parent.Collect() {
+			return np
+		}
+	}
+
+	return nil
+}
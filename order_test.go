@@ -0,0 +1,297 @@
+package radix
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// Compares the navigation methods against a brute force search of the
+// sorted keys
+func TestNavigation(t *testing.T) {
+
+	keys := []string{
+		"rab", "rabbi", "rabbit", "romane", "romanus", "romulus", "ruber",
+		"rubens", "rubicon", "rubicundus",
+	}
+
+	r := NewRadixTree()
+	for _, key := range keys {
+		r.Add(key, identifier{key})
+	}
+	sort.Strings(keys)
+
+	probes := append([]string{
+		"", "a", "r", "ra", "rabb", "rabbits", "rom", "romanz", "ru",
+		"rubicz", "rubicundusx", "s", "zzz",
+	}, keys...)
+
+	type navigation func(string) (string, interface{}, bool)
+	testCases := []struct {
+		Name   string
+		Method navigation
+		Expect func(string) (string, bool)
+	}{
+		{
+			Name:   "Floor",
+			Method: r.Floor,
+			Expect: func(probe string) (string, bool) {
+				i := sort.SearchStrings(keys, probe)
+				if i < len(keys) && keys[i] == probe {
+					return probe, true
+				}
+				if i == 0 {
+					return "", false
+				}
+				return keys[i-1], true
+			},
+		},
+		{
+			Name:   "Ceiling",
+			Method: r.Ceiling,
+			Expect: func(probe string) (string, bool) {
+				i := sort.SearchStrings(keys, probe)
+				if i == len(keys) {
+					return "", false
+				}
+				return keys[i], true
+			},
+		},
+		{
+			Name:   "Next",
+			Method: r.Next,
+			Expect: func(probe string) (string, bool) {
+				i := sort.Search(len(keys), func(i int) bool {
+					return keys[i] > probe
+				})
+				if i == len(keys) {
+					return "", false
+				}
+				return keys[i], true
+			},
+		},
+		{
+			Name:   "Prev",
+			Method: r.Prev,
+			Expect: func(probe string) (string, bool) {
+				i := sort.SearchStrings(keys, probe)
+				if i == 0 {
+					return "", false
+				}
+				return keys[i-1], true
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		for _, probe := range probes {
+
+			key, content, ok := test.Method(probe)
+			expectKey, expectOk := test.Expect(probe)
+
+			if key != expectKey || ok != expectOk {
+				t.Errorf("%s(%s) gave %s (%t), expected %s (%t)",
+					test.Name, probe, key, ok, expectKey, expectOk)
+			}
+
+			if ok && content != (identifier{key}) {
+				t.Errorf("%s(%s) did not return valid content", test.Name, probe)
+			}
+		}
+	}
+
+	if key, _, _ := r.Min(); key != "rab" {
+		t.Errorf("Expected min to be rab, got %s", key)
+	}
+	if key, _, _ := r.Max(); key != "rubicundus" {
+		t.Errorf("Expected max to be rubicundus, got %s", key)
+	}
+
+	empty := NewRadixTree()
+	if _, _, ok := empty.Min(); ok {
+		t.Errorf("Expected no min in an empty tree")
+	}
+	if _, _, ok := empty.Max(); ok {
+		t.Errorf("Expected no max in an empty tree")
+	}
+	if _, _, ok := empty.Ceiling("a"); ok {
+		t.Errorf("Expected no ceiling in an empty tree")
+	}
+}
+
+// Stepping with Next and Prev should visit the integration tree in the same
+// order as walking it
+func TestNavigationIntegration(t *testing.T) {
+
+	r := buildIntegrationTree()
+	all, _ := r.PrefixSearch("")
+
+	stepped := []string{}
+	for key, _, ok := r.Min(); ok; key, _, ok = r.Next(key) {
+		stepped = append(stepped, key)
+	}
+
+	if len(stepped) != len(all) {
+		t.Fatalf("Stepping forward found %d keys, expected %d",
+			len(stepped), len(all))
+	}
+
+	i := len(all) - 1
+	for key, _, ok := r.Max(); ok; key, _, ok = r.Prev(key) {
+		if key != all[i] {
+			t.Fatalf("Stepping backward found %s, expected %s", key, all[i])
+		}
+		i--
+	}
+
+	if i != -1 {
+		t.Errorf("Stepping backward stopped %d keys early", i+1)
+	}
+}
+
+// Ranges should give the same keys as filtering every key
+func TestRange(t *testing.T) {
+
+	r := buildIntegrationTree()
+	all, _ := r.PrefixSearch("")
+
+	testCases := []struct {
+		From string
+		To   string
+	}{
+		{From: "kt1", To: "kt6"},
+		{From: "s", To: "t"},
+		{From: "somerset road", To: "somerset road, royal borough of kingston upon thames"},
+		{From: "tesco", To: "tesco"},
+		{From: "", To: "b"},
+		{From: "z", To: "a"},
+		{From: "avenida", To: "avenida de pablo iglesias, alcobendas"},
+	}
+
+	for _, test := range testCases {
+		for _, flags := range [][2]bool{
+			{true, true}, {true, false}, {false, true}, {false, false},
+		} {
+
+			expected := []string{}
+			for _, key := range all {
+				if (key > test.From || (flags[0] && key == test.From)) &&
+					(key < test.To || (flags[1] && key == test.To)) {
+					expected = append(expected, key)
+				}
+			}
+
+			got := []string{}
+			r.Range(test.From, test.To, flags[0], flags[1],
+				func(key string, value interface{}) bool {
+					got = append(got, key)
+					return true
+				})
+
+			if !reflect.DeepEqual(got, expected) {
+				t.Errorf("Range %s to %s %v gave %d keys, expected %d",
+					test.From, test.To, flags, len(got), len(expected))
+			}
+		}
+	}
+
+	// Stopping early
+	count := 0
+	r.Range("s", "t", true, true, func(key string, value interface{}) bool {
+		count++
+		return count < 5
+	})
+	if count != 5 {
+		t.Errorf("Expected range to stop after 5 keys, got %d", count)
+	}
+}
+
+// Counts, ranks and selection should agree with the sorted keys, including
+// once keys have been deleted
+func TestRankAndSelect(t *testing.T) {
+
+	keys := []string{
+		"rab", "rabbi", "rabbit", "romane", "romanus", "romulus", "ruber",
+		"rubens", "rubicon", "rubicundus",
+	}
+
+	r := NewRadixTree()
+	for _, key := range keys {
+		r.Add(key, identifier{key})
+	}
+	r.Add("rabbi", identifier{"rabbi"})
+	r.Add("zebra", identifier{"zebra"})
+	r.Delete("zebra")
+	r.Add("rub", identifier{"rub"})
+	r.Add("rubens", identifier{"rubens"})
+	r.Add("rubicz", identifier{"rubicz"})
+	r.DeletePrefix("rubicz")
+	r.Delete("rub")
+
+	sort.Strings(keys)
+
+	if r.root.Size() != len(keys) {
+		t.Fatalf("Expected root size of %d, got %d", len(keys), r.root.Size())
+	}
+
+	for i, key := range keys {
+
+		if rank := r.Rank(key); rank != i {
+			t.Errorf("Expected rank of %s to be %d, got %d", key, i, rank)
+		}
+
+		selected, content, ok := r.Select(i)
+		if !ok || selected != key || content != (identifier{key}) {
+			t.Errorf("Expected select %d to be %s, got %s", i, key, selected)
+		}
+	}
+
+	for _, probe := range []string{
+		"", "a", "r", "rabbits", "rom", "romanz", "rubicundusx", "zzz",
+	} {
+		expected := sort.SearchStrings(keys, probe)
+		if rank := r.Rank(probe); rank != expected {
+			t.Errorf("Expected rank of %s to be %d, got %d",
+				probe, expected, rank)
+		}
+	}
+
+	if _, _, ok := r.Select(len(keys)); ok {
+		t.Errorf("Expected nothing to be selected past the end")
+	}
+	if _, _, ok := r.Select(-1); ok {
+		t.Errorf("Expected nothing to be selected before the start")
+	}
+
+	prefixCases := map[string]int{
+		"":      10,
+		"r":     10,
+		"rab":   3,
+		"rabb":  2,
+		"rom":   3,
+		"rubi":  2,
+		"rubic": 2,
+		"x":     0,
+	}
+
+	for prefix, expected := range prefixCases {
+		if count := r.CountPrefix(prefix); count != expected {
+			t.Errorf("Expected %d keys for prefix %s, got %d",
+				expected, prefix, count)
+		}
+	}
+}
+
+// Counts should match the number of keys searching finds
+func TestCountPrefixIntegration(t *testing.T) {
+
+	r := buildIntegrationTree()
+
+	for _, prefix := range []string{"", "s", "somer", "tesorería", "kt"} {
+		keys, _ := r.PrefixSearch(prefix)
+		if count := r.CountPrefix(prefix); count != len(keys) {
+			t.Errorf("Expected %d keys for prefix %s, got %d",
+				len(keys), prefix, count)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package radix
 
 import (
+	"bytes"
 	"fmt"
 	"strings"
 )
@@ -9,44 +10,120 @@ const (
 	fuzzyIterationLimit = 2
 )
 
-// RadixTree wraps the root, provides all functionality to add, search
-// and so on.
-type RadixTree struct {
-	root        *radixNode
+// Tree wraps the root, provides all functionality to add, search
+// and so on. The content stored against each key is of type V so it comes
+// back out of searches without needing type assertions
+type Tree[V any] struct {
+	root        *treeNode[V]
 	stringCount int
 	nodeCount   int
+
+	// Nodes from any other generation are shared with a snapshot, and
+	// must be copied before they are changed
+	generation uint64
+
+	// Encodes the content when the tree is serialised, gob if not set
+	codec Codec[V]
+
+	// The settings the tree was made with
+	options treeOptions
+}
+
+// RadixTree is a Tree which can hold content of any type, needing type
+// inference when it comes out
+type RadixTree = Tree[interface{}]
+
+// NewTree sets up and returns a Tree struct for content of type V, the
+// options change how it is built
+func NewTree[V any](opts ...Option) *Tree[V] {
+	return newTree[V](newOptions(opts...))
+}
+
+// Builds the zero-value radix tree with the settings given
+func newTree[V any](options treeOptions) *Tree[V] {
+
+	generation := nextGeneration()
+	return &Tree[V]{
+		root:       &treeNode[V]{generation: generation},
+		generation: generation,
+		options:    options,
+	}
+}
+
+// NewRadixTree sets up and returns a RadixTree struct, the options change
+// how it is built
+func NewRadixTree(opts ...Option) *RadixTree {
+	return NewTree[interface{}](opts...)
 }
 
-// NewRadixTree sets up and returns a RadixTree struct
-func NewRadixTree() *RadixTree {
+// SearchOptions bounds the results of a search, so that the cost of the
+// search depends on the number of results wanted rather than the number that
+// exist
+type SearchOptions struct {
 
-	// Build the zero-value radix tree
-	return &RadixTree{
-		root: &radixNode{},
+	// The maximum number of results to return, 0 returns them all
+	Limit int
+
+	// The number of results to skip before collecting, used for paging
+	Offset int
+}
+
+// Returns the options with negative limits and offsets treated as 0
+func (opts SearchOptions) clamped() SearchOptions {
+	return SearchOptions{
+		Limit:  max(opts.Limit, 0),
+		Offset: max(opts.Offset, 0),
 	}
 }
 
 // FuzzySearch launches the fuzzySearch() method if the search is valid. Here,
 // unlike with prefix search, we do not accept an empty string.
-func (tree *RadixTree) FuzzySearch(
+func (tree *Tree[V]) FuzzySearch(
+	str string,
+) ([]string, []V) {
+	return tree.FuzzySearchWithOptions(str, SearchOptions{})
+}
+
+// FuzzySearchWithOptions performs a fuzzy search, stopping as soon as enough
+// results have been found
+func (tree *Tree[V]) FuzzySearchWithOptions(
 	str string,
-) ([]string, []interface{}) {
+	opts SearchOptions,
+) ([]string, []V) {
+
+	keys := []string{}
+	content := []V{}
 
 	if len(tree.root.Children()) == 0 {
-		return []string{}, []interface{}{}
+		return keys, content
 	}
 
 	if str == "" {
-		return []string{}, []interface{}{}
+		return keys, content
 	}
 
-	return tree.fuzzySearch(
+	opts = opts.clamped()
+	skip := opts.Offset
+	tree.fuzzySearch(
 		tree.stringToBytes(str),
 		tree.root,
 		0,
 		0,
-		0,
-		[]byte{})
+		-1,
+		-1,
+		[]byte{},
+		func(result FuzzyResult[V]) bool {
+			if skip > 0 {
+				skip--
+				return true
+			}
+
+			keys = append(keys, result.Key)
+			content = append(content, result.Content)
+			return opts.Limit == 0 || len(keys) < opts.Limit
+		})
+
+	return keys, content
 }
 
 // fuzzySearch performs a non-prefix search with some element of 'fuzz',
@@ -55,39 +132,75 @@ func (tree *RadixTree) FuzzySearch(
 //
 // The fuzziness is achieved through bitwise operations that check if under a
 // given node, the letters we are searching for exist. If they do then descend
-func (tree *RadixTree) fuzzySearch(
+//
+// The iteration is the position in the key we're at, the first and last
+// increments are the positions where the first and last letters of the search
+// were matched. They are kept on the results so they can be ranked
+//
+// Each result is passed to the function as it is found, returning false
+// stops the search. The return value is false if the search was stopped
+func (tree *Tree[V]) fuzzySearch(
 	str []byte,
-	node *radixNode,
+	node *treeNode[V],
 	index int,
 	iteration int,
+	firstIncrement int,
 	lastIncrement int,
 	found []byte,
-) ([]string, []interface{}) {
+	fn func(FuzzyResult[V]) bool,
+) bool {
+
+	// The string may have normalised to nothing, which matches nothing
+	if len(str) == 0 {
+		return true
+	}
+
+	searchBitMask := tree.masker()(str[index:])
 
-	searchBitMask := genBitMask(str[index:])
-	collectedKeys := []string{}
-	collectedContent := []interface{}{}
+	var searchBigrams bigramSignature
+	if tree.options.bigrams {
+		searchBigrams = genBigrams(str[index:], tree.masker())
+	}
 
 	if len(node.Children()) == 0 {
-		return []string{}, []interface{}{}
+		return true
 	}
 
 	startIndex := index
+	startFirst := firstIncrement
+	startLast := lastIncrement
 
 	for _, child := range node.Children() {
 
-		// Reset index for each iteration of the child
+		// Reset for each iteration of the child
 		index = startIndex
+		iteration = len(found)
+		firstIncrement = startFirst
+		lastIncrement = startLast
 
 		// If this is the case, then somewhere inside the depth of this
 		// node there MIGHT exist what we're looking for, or it could
 		// be shallow
-		if child.IsBitMaskSet(searchBitMask) {
+		if child.IsBitMaskSet(searchBitMask) &&
+			child.IsBigramSet(&searchBigrams) {
 
 			// Iterate letters
 			for _, letter := range child.Key() {
 				if index < len(str) {
+
+					// Part way through a multi-byte letter and it no
+					// longer matches, so start over on that letter
+					if letter != str[index] {
+						index = runeStartIndex(str, index)
+						if index == 0 {
+							firstIncrement = -1
+						}
+					}
+
 					if letter == str[index] {
+						if index == 0 {
+							firstIncrement = iteration
+						}
 						lastIncrement = iteration
 						index++
 					}
@@ -102,24 +215,41 @@ func (tree *RadixTree) fuzzySearch(
 
 			if index >= len(str) {
 
-				colKeys, colContent := tree.collect(
+				start := firstIncrement
+				span := lastIncrement - firstIncrement + 1
+
+				more := tree.walk(
 					child,
 					append(found, child.Key()...),
-				)
-				collectedKeys = append(collectedKeys, colKeys...)
-				collectedContent = append(collectedContent, colContent...)
+					func(key []byte, node *treeNode[V]) bool {
+						return fn(FuzzyResult[V]{
+							Key:     node.originalKey(key),
+							Content: node.Content(),
+							Start:   start,
+							Span:    span,
+							Gaps:    span - len(str),
+						})
+					})
+
+				if !more {
+					return false
+				}
 			} else {
 
-				colKeys, colContent := tree.fuzzySearch(
+				more := tree.fuzzySearch(
 					str,
 					child,
 					index,
 					iteration,
+					firstIncrement,
 					lastIncrement,
 					append(found, child.Key()...),
+					fn,
 				)
-				collectedKeys = append(collectedKeys, colKeys...)
-				collectedContent = append(collectedContent, colContent...)
+
+				if !more {
+					return false
+				}
 			}
 		} else {
 			// Not set, can't do anything here really
@@ -127,7 +257,7 @@ func (tree *RadixTree) fuzzySearch(
 		}
 	}
 
-	return collectedKeys, collectedContent
+	return true
 }
 
 // PrefixSearch executes the fastest form of search, whereby it iterates
@@ -135,12 +265,21 @@ func (tree *RadixTree) fuzzySearch(
 // collecting on that node.
 //
 // Search with an empty string should return everything
-func (tree *RadixTree) PrefixSearch(
+func (tree *Tree[V]) PrefixSearch(
+	str string,
+) ([]string, []V) {
+	return tree.PrefixSearchWithOptions(str, SearchOptions{})
+}
+
+// PrefixSearchWithOptions performs a prefix search, stopping as soon as
+// enough results have been collected
+func (tree *Tree[V]) PrefixSearchWithOptions(
 	str string,
-) ([]string, []interface{}) {
+	opts SearchOptions,
+) ([]string, []V) {
 
 	if len(tree.root.Children()) == 0 {
-		return []string{}, []interface{}{}
+		return []string{}, []V{}
 	}
 
 	node, prefix, ok := tree.prefixSearch(
@@ -150,41 +289,201 @@ func (tree *RadixTree) PrefixSearch(
 		[]byte{})
 
 	if !ok {
-		return []string{}, []interface{}{}
+		return []string{}, []V{}
 	}
 
-	return tree.collect(node, prefix)
+	return tree.collect(node, prefix, opts)
 }
 
-// Returns the longest prefix (as a string) that is found. It is like a prefix
-// search without the collect
-func (tree *RadixTree) LongestPrefix(str string) (string, bool) {
+// CountPrefix returns the number of keys beginning with the prefix. Each node
+// keeps count of the keys below it, so nothing is collected
+func (tree *Tree[V]) CountPrefix(str string) int {
 
-	if len(tree.root.Children()) == 0 {
-		return "", false
-	}
-
-	ok := false
-	_, prefix, _ := tree.prefixSearch(
-		[]byte(str),
+	node, _, ok := tree.prefixSearch(
+		tree.stringToBytes(str),
 		tree.root,
 		0,
 		[]byte{})
 
-	if len(prefix) > 0 {
-		ok = true
+	if !ok {
+		return 0
+	}
+
+	return node.Size()
+}
+
+// Get returns the content stored against an exact key, and whether the key
+// exists. Unlike PrefixSearch nothing below the node is collected
+func (tree *Tree[V]) Get(str string) (V, bool) {
+
+	node := tree.find(str)
+	if node == nil {
+		var zero V
+		return zero, false
+	}
+
+	return node.Content(), true
+}
+
+// Contains checks whether the exact key has been added to the tree
+func (tree *Tree[V]) Contains(str string) bool {
+	return tree.find(str) != nil
+}
+
+// Update sets the content for a key based on what is currently stored
+// against it. The function receives the existing content and whether the key
+// exists, if it doesn't then the key is added with the returned content
+func (tree *Tree[V]) Update(
+	str string,
+	fn func(content V, exists bool) V,
+) {
+
+	if str == "" {
+		return
+	}
+
+	path := tree.findPath(str)
+	if path == nil {
+		var zero V
+		tree.Add(str, fn(zero, false))
+		return
+	}
+
+	tree.writablePath(path)
+	node := path[len(path)-1]
+	node.SetContent(fn(node.Content(), true))
+}
+
+// Finds the node for an exact key, the whole of the key must be consumed and
+// the node must be one which was inserted. Returns nil if it is not found
+func (tree *Tree[V]) find(str string) *treeNode[V] {
+
+	path := tree.findPath(str)
+	if path == nil {
+		return nil
+	}
+
+	return path[len(path)-1]
+}
+
+// The same as find, except that every node passed through from the root is
+// returned. This is needed for making changes, as nodes don't know their
+// parent
+func (tree *Tree[V]) findPath(str string) []*treeNode[V] {
+
+	if str == "" {
+		return nil
+	}
+
+	path := tree.path(tree.stringToBytes(str), false)
+	if path == nil || !path[len(path)-1].Collect() {
+		return nil
+	}
+
+	return path
+}
+
+// Follows the input down the tree, returning every node passed through from
+// the root to the node where the input ends. If partial is set the input may
+// end part way through the last node, otherwise it must end exactly at the
+// end of it. Returns nil if the input can't be followed
+func (tree *Tree[V]) path(input []byte, partial bool) []*treeNode[V] {
+
+	node := tree.root
+	path := []*treeNode[V]{node}
+	index := 0
+
+	for index < len(input) {
+
+		rest := input[index:]
+		var next *treeNode[V]
+
+		for _, child := range node.ChildrenStartingWith(rest[0]) {
+			if bytes.HasPrefix(rest, child.Key()) ||
+				(partial && bytes.HasPrefix(child.Key(), rest)) {
+				next = child
+				break
+			}
+		}
+
+		if next == nil {
+			return nil
+		}
+
+		node = next
+		path = append(path, node)
+		index += len(node.Key())
+	}
+
+	return path
+}
+
+// Makes every node along a path safe to change. Any which are shared with a
+// snapshot are copied, with the copies linked in place of the originals
+func (tree *Tree[V]) writablePath(path []*treeNode[V]) {
+
+	tree.root = tree.root.writable(tree.generation)
+	path[0] = tree.root
+
+	for i := 1; i < len(path); i++ {
+		parent := path[i-1]
+		index := parent.childIndex(path[i])
+
+		path[i] = path[i].writable(tree.generation)
+		parent.children[index] = path[i]
+	}
+}
+
+// Returns the longest key that is a prefix of the string, and whether one
+// was found. It is like a prefix search without the collect, going the other
+// way
+func (tree *Tree[V]) LongestPrefix(str string) (string, bool) {
+
+	input := tree.stringToBytes(str)
+	node := tree.root
+	index := 0
+	longest := -1
+	var found *treeNode[V]
+
+	// Descend for as long as whole keys match, remembering the last node
+	// which was inserted
+	for node != nil && index < len(input) {
+
+		var next *treeNode[V]
+		for _, child := range node.ChildrenStartingWith(input[index]) {
+			if bytes.HasPrefix(input[index:], child.Key()) {
+				next = child
+				break
+			}
+		}
+
+		if next == nil {
+			break
+		}
+
+		index += len(next.Key())
+		if next.Collect() {
+			longest = index
+			found = next
+		}
+
+		node = next
+	}
+
+	if longest < 0 {
+		return "", false
 	}
 
-	return string(prefix), ok
+	return found.originalKey(input[:longest]), true
 }
 
 // Recursively prefix-searches to find the longest prefix that exists
-func (tree *RadixTree) prefixSearch(
+func (tree *Tree[V]) prefixSearch(
 	str []byte,
-	node *radixNode,
+	node *treeNode[V],
 	index int,
 	found []byte,
-) (*radixNode, []byte, bool) {
+) (*treeNode[V], []byte, bool) {
 
 	if index+1 > len(str) {
 		return node, found, true
@@ -194,7 +493,7 @@ func (tree *RadixTree) prefixSearch(
 		return node, found, false
 	}
 
-	for _, child := range node.Children() {
+	for _, child := range node.ChildrenStartingWith(str[index]) {
 
 		lettersFound := 0
 		searchLetter := str[index]
@@ -231,154 +530,296 @@ func (tree *RadixTree) prefixSearch(
 }
 
 // The collection will, starting from a given node, recurse and generate
-// strings from every leaf
-func (tree *RadixTree) collect(
-	node *radixNode,
+// strings from every key below it. The options can be used to skip some and
+// stop once there are enough
+func (tree *Tree[V]) collect(
+	node *treeNode[V],
 	prefix []byte,
-) ([]string, []interface{}) {
-
-	if len(node.Children()) == 0 {
-		return []string{string(prefix)},
-			[]interface{}{node.Content()}
-	}
+	opts SearchOptions,
+) ([]string, []V) {
 
 	collectedStrings := []string{}
-	collectedContent := []interface{}{}
+	collectedContent := []V{}
+	opts = opts.clamped()
+	skip := opts.Offset
+
+	tree.walk(node, prefix, func(key []byte, node *treeNode[V]) bool {
+		if skip > 0 {
+			skip--
+			return true
+		}
 
-	if node.Collect() {
-		collectedStrings = append(collectedStrings, string(prefix))
+		collectedStrings = append(collectedStrings, node.originalKey(key))
 		collectedContent = append(collectedContent, node.Content())
+		return opts.Limit == 0 || len(collectedStrings) < opts.Limit
+	})
+
+	return collectedStrings, collectedContent
+}
+
+// Walks in order over every key from a given node down, calling the function
+// with the full key and the node that holds it. The key is only valid for the
+// duration of the call. Returning false from the function stops the walk, and
+// the walk returns false to say it was stopped
+func (tree *Tree[V]) walk(
+	node *treeNode[V],
+	prefix []byte,
+	fn func(key []byte, node *treeNode[V]) bool,
+) bool {
+
+	if node.Collect() && !fn(prefix, node) {
+		return false
 	}
 
-	// Recursively append
+	// Recursively walk
 	for _, child := range node.Children() {
-		bytes := append(prefix, child.Key()...)
-		colKeys, colContent := tree.collect(child, bytes)
-		collectedStrings = append(collectedStrings, colKeys...)
-		collectedContent = append(collectedContent, colContent...)
+		if !tree.walk(child, append(prefix, child.Key()...), fn) {
+			return false
+		}
 	}
 
-	return collectedStrings, collectedContent
+	return true
 }
 
-// Add inserts a string into the trie, it returns the node that it
-// inserts (for testing purposes)
-func (tree *RadixTree) Add(
-	str string, content interface{}) *radixNode {
+// Add inserts a string into the trie, if the key already exists then the
+// content is replaced. It returns the node that it inserts (for testing
+// purposes) and whether the key was new
+func (tree *Tree[V]) Add(
+	str string, content V) (*treeNode[V], bool) {
 
 	// Bail out if null
 	if str == "" {
-		return &radixNode{}
+		return &treeNode[V]{}, false
 	}
 
-	// Convert input to byte slice
+	// Convert input to byte slice, normalising may leave nothing
 	input := tree.stringToBytes(str)
+	if len(input) == 0 {
+		return &treeNode[V]{}, false
+	}
 
-	bitMask := genBitMask(input)
-	leaf := tree.add(tree.root, input, bitMask, 0)
-	tree.stringCount++
+	tree.root = tree.root.writable(tree.generation)
+	path := tree.add(tree.root, input, []*treeNode[V]{tree.root})
+	tree.rebuildBigrams(path)
+	leaf := path[len(path)-1]
+
+	// Only count the string if it's one we haven't seen before
+	isNew := !leaf.Collect()
+	if isNew {
+		tree.stringCount++
+		leaf.SetToCollect()
+		addSize(path, 1)
+	}
 
 	// Set the content only on the leaf node
-	leaf.SetToCollect()
-
 	leaf.SetContent(content)
-	return leaf
+	leaf.setOriginal(str, input)
+	return leaf, isNew
+}
+
+// AddIfAbsent inserts the key only if it doesn't already exist, existing
+// content is left alone. Returns whether the key was added
+func (tree *Tree[V]) AddIfAbsent(str string, content V) bool {
+
+	if str == "" || tree.find(str) != nil {
+		return false
+	}
+
+	_, isNew := tree.Add(str, content)
+	return isNew
+}
+
+// Replace sets the content of a key only if it already exists, it will not
+// insert. Returns whether the key was found
+func (tree *Tree[V]) Replace(str string, content V) bool {
+
+	path := tree.findPath(str)
+	if path == nil {
+		return false
+	}
+
+	tree.writablePath(path)
+	path[len(path)-1].SetContent(content)
+	return true
 }
 
-// The brains behind the adding, handles all cases for adding new keys
-func (tree *RadixTree) add(
-	node *radixNode,
+// Len returns the number of distinct keys held in the tree
+func (tree *Tree[V]) Len() int {
+	return tree.stringCount
+}
+
+// The brains behind the adding, handles all cases for adding new keys. The
+// path of nodes from the root is built up as we descend, the last of which
+// holds the end of the input (this may be a node which already existed).
+// Every node along the path is one which this tree is able to change
+func (tree *Tree[V]) add(
+	node *treeNode[V],
 	input []byte,
-	bitMask uint32,
-	depth int,
-) *radixNode {
+	path []*treeNode[V],
+) []*treeNode[V] {
 
 	// Recursion down to 0 means we're all out and we should return
 	if len(input) == 0 {
-		return node
+		return path
 	}
 
-	// If there are no children, I.e. this is the top element
-	if len(node.Children()) == 0 {
-		tree.nodeCount++
-		return node.NewChild(input)
-	}
+	start, end := node.childRange(input[0])
+	for i := start; i < end; i++ {
+
+		child := node.Children()[i]
 
-	// Loop through children
-	for childIndex, child := range node.Children() {
+		// Count the letters the input has in common with the key
+		key := child.Key()
+		common := 0
+		for common < len(key) &&
+			common < len(input) &&
+			key[common] == input[common] {
+			common++
+		}
 
-		// Loop through the letters that make up the key
-		for i := 0; i < len(child.Key()); i++ {
+		// Nothing in common, it may exist in a sibling
+		if common == 0 {
+			continue
+		}
 
-			// Add some safety to the input
-			if i > len(input) {
-				break
+		// Never split part way through a letter, step back to where it
+		// starts. If that's the very beginning it belongs to a sibling
+		if common < len(key) {
+			common = runeStartIndex(key, common)
+			if common == 0 {
+				continue
 			}
+		}
+
+		// The child is going to change, so it can't be shared
+		child = child.writable(tree.generation)
+		node.children[i] = child
+		path = append(path, child)
 
-			var inputbyte byte
-			if i+1 <= len(input) {
-				inputbyte = input[i : i+1][0]
+		// Everything we add from here lives below the child
+		child.OrBitMask(tree.masker()(input))
+
+		// The key has been matched entirely, either this is the node or
+		// we continue down
+		if common == len(key) {
+			if common == len(input) {
+				return path
 			}
+			return tree.add(child, input[common:], path)
+		}
 
-			childbyte := child.Key()[i : i+1][0]
+		// Otherwise the input diverges (or finishes) part way through the
+		// key, break the child at that point into 2 nodes
+		tree.nodeCount++
+		child.Break(common, tree.masker())
 
-			// If the letter is a match
-			if childbyte == inputbyte {
+		// If the input finishes at the break then the child (which is
+		// now the prefix) is the node, otherwise the remainder goes
+		// beneath it
+		if common == len(input) {
+			return path
+		}
 
-				child.OrBitMask(genBitMask(input[i:]))
+		tree.nodeCount++
+		return append(path, child.NewChild(input[common:], tree.masker()))
+	}
 
-				// Are we on the last character of keys?
-				if i+1 == len(child.Key()) {
+	// No children share a first letter, so it becomes a new child
+	tree.nodeCount++
+	return append(path, node.NewChild(input, tree.masker()))
+}
 
-					// Are there more children?
-					if len(child.Children()) > 0 {
-						return tree.add(child, input[i+1:], bitMask, depth+1)
-					}
-				}
-			} else {
+// Delete removes a single key from the tree, returning whether it was there
+// to be removed. Nodes which are no longer needed are pruned and any which
+// are left with a single child are merged back together
+func (tree *Tree[V]) Delete(str string) bool {
+
+	path := tree.findPath(str)
+	if path == nil {
+		return false
+	}
 
-				// Else it's not a match, check if we've exhausted the
-				// chance of it existing in another sibling, then:
-				if i > 0 {
+	tree.writablePath(path)
+	node := path[len(path)-1]
 
-					// Break the child at i into 2 nodes
-					tree.nodeCount++
-					child.Break(i)
+	var zero V
+	node.doCollect = false
+	node.original = ""
+	node.SetContent(zero)
+	tree.stringCount--
+	addSize(path, -1)
 
-					if len(input[i:]) > 0 {
-						tree.nodeCount++
-						newNode := child.NewChild(input[i:])
-						return newNode
-					} else {
+	tree.compact(path)
+	return true
+}
 
-						// If the break is less than the input then
-						// return the child (which is the parent of any
-						// new child)
-						return child
-					}
-				} else {
+// DeletePrefix removes every key beginning with the prefix, returning the
+// number of keys which were removed. An empty prefix will empty the tree
+func (tree *Tree[V]) DeletePrefix(str string) int {
 
-					// If there are more nodes to be seen, continue
-					if childIndex+1 < len(node.Children()) {
-						break
-					}
+	input := tree.stringToBytes(str)
+	if len(input) == 0 {
+		removed := tree.stringCount
+		tree.root = &treeNode[V]{generation: tree.generation}
+		tree.stringCount = 0
+		tree.nodeCount = 0
+		return removed
+	}
 
-					// If it's the first letter, just insert to node
-					// (not child)
-					tree.nodeCount++
-					newNode := node.NewChild(input[i:])
-					return newNode
-				}
+	path := tree.path(input, true)
+	if path == nil {
+		return 0
+	}
+
+	// Everything below the node shares the prefix, so the whole branch
+	// goes. Only the nodes above it are changed
+	node := path[len(path)-1]
+	path = path[:len(path)-1]
+	tree.writablePath(path)
+
+	keys, nodes := node.count()
+	addSize(path, -keys)
+	path[len(path)-1].RemoveChild(node)
+
+	tree.stringCount -= keys
+	tree.nodeCount -= nodes
+
+	tree.compact(path)
+	return keys
+}
+
+// Works up a path of nodes from the root after something has been removed
+// below it. Empty nodes are dropped, nodes with a single child are merged
+// with that child and the bit masks are regenerated so the fuzzy search
+// doesn't descend for letters which no longer exist. The path must be
+// writable
+func (tree *Tree[V]) compact(path []*treeNode[V]) {
+
+	for i := len(path) - 1; i > 0; i-- {
+
+		node := path[i]
+		parent := path[i-1]
+
+		if !node.Collect() {
+			switch len(node.Children()) {
+			case 0:
+				parent.RemoveChild(node)
+				tree.nodeCount--
+			case 1:
+				node.Merge(tree.masker())
+				tree.nodeCount--
 			}
 		}
+
+		node.RebuildBitMask(tree.masker())
 	}
 
-	return node
+	tree.rebuildBigrams(path)
 }
 
 // String generates an ASCII tree to allow the data structure to be
 // visualised
-func (rt *RadixTree) String() string {
+func (rt *Tree[V]) String() string {
 
 	output := "\n"
 	first := true
@@ -411,20 +852,9 @@ func (rt *RadixTree) String() string {
 	return output
 }
 
-// Because we're converting from utf8 down, we'll max out at 255 on the
-// letter's value as to not overflow a byte
-func (rt *RadixTree) stringToBytes(str string) []byte {
-
-	bytes := make([]byte, len(str))
-
-	for i, letter := range str {
-		if letter > rune(255) {
-			// This is ignored
-			// bytes[i] = 0
-		} else {
-			bytes[i] = uint8(letter)
-		}
-	}
-
-	return bytes
+// Keys are stored as their UTF-8 bytes, so letters outside of ASCII take up
+// more than one byte. Nodes are only ever split between whole letters. The
+// keys and the strings searched for are both normalised here
+func (rt *Tree[V]) stringToBytes(str string) []byte {
+	return rt.options.normalisation.normalise(str)
 }
@@ -1,6 +1,9 @@
 package radix
 
-import "testing"
+import (
+	"math/rand"
+	"testing"
+)
 
 func BenchmarkBuildTrie(b *testing.B) {
 
@@ -10,6 +13,33 @@ func BenchmarkBuildTrie(b *testing.B) {
 	}
 }
 
+// Benchmarks building the integration tree in one pass from its keys, already
+// in order
+func BenchmarkBuildFromSorted(b *testing.B) {
+
+	keys, content := buildIntegrationTree().PrefixSearch("")
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		BuildFromSorted(pairs(keys, content))
+	}
+}
+
+// Benchmarks building the integration tree in one pass from its keys in the
+// order they are added, so they must be sorted first
+func BenchmarkBuildFromUnsorted(b *testing.B) {
+
+	keys, content := buildIntegrationTree().PrefixSearch("")
+	rand.New(rand.NewSource(1)).Shuffle(len(keys), func(i, j int) {
+		keys[i], keys[j] = keys[j], keys[i]
+	})
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		BuildFromUnsorted(pairs(keys, content))
+	}
+}
+
 // Benchmarks a prefix search for 'Som'
 func BenchmarkPrefixSom(b *testing.B) {
 
@@ -49,3 +79,110 @@ func BenchmarkFuzzySomer(b *testing.B) {
 		trie.FuzzySearch("somer")
 	}
 }
+
+// Benchmarks a prefix search for 's', which matches a large part of the tree
+func BenchmarkPrefixS(b *testing.B) {
+
+	trie := buildIntegrationTree()
+
+	for i := 0; i < b.N; i++ {
+		trie.PrefixSearch("s")
+	}
+}
+
+// Benchmarks a prefix search for 's' only wanting the first 10 results
+func BenchmarkPrefixSLimit10(b *testing.B) {
+
+	trie := buildIntegrationTree()
+
+	for i := 0; i < b.N; i++ {
+		trie.PrefixSearchWithOptions("s", SearchOptions{Limit: 10})
+	}
+}
+
+// Benchmarks a fuzzy search for 's' only wanting the first 10 results
+func BenchmarkFuzzySLimit10(b *testing.B) {
+
+	trie := buildIntegrationTree()
+
+	for i := 0; i < b.N; i++ {
+		trie.FuzzySearchWithOptions("s", SearchOptions{Limit: 10})
+	}
+}
+
+// Builds the integration tree with 64 bit masks
+func buildIntegrationTree64(b *testing.B) *RadixTree {
+
+	keys, content := buildIntegrationTree().PrefixSearch("")
+	trie, err := BuildFromSorted(pairs(keys, content), WithMaskMode(Mask64))
+	if err != nil {
+		b.Fatalf("Expected to build the tree, got %s", err)
+	}
+
+	b.ResetTimer()
+	return trie
+}
+
+// Benchmarks a fuzzy search for 'Somer' with 64 bit masks
+func BenchmarkFuzzySomerMask64(b *testing.B) {
+
+	trie := buildIntegrationTree64(b)
+
+	for i := 0; i < b.N; i++ {
+		trie.FuzzySearch("somer")
+	}
+}
+
+// Benchmarks a fuzzy search with punctuation in it, which the 32 bit masks
+// can't tell apart
+func BenchmarkFuzzyRoadComma(b *testing.B) {
+
+	trie := buildIntegrationTree()
+
+	for i := 0; i < b.N; i++ {
+		trie.FuzzySearch("rd, k")
+	}
+}
+
+// Benchmarks a fuzzy search with punctuation in it with 64 bit masks
+func BenchmarkFuzzyRoadCommaMask64(b *testing.B) {
+
+	trie := buildIntegrationTree64(b)
+
+	for i := 0; i < b.N; i++ {
+		trie.FuzzySearch("rd, k")
+	}
+}
+
+// Builds the integration tree with bigram signatures
+func buildIntegrationTreeBigrams(b *testing.B) *RadixTree {
+
+	keys, content := buildIntegrationTree().PrefixSearch("")
+	trie, err := BuildFromSorted(pairs(keys, content), WithBigrams())
+	if err != nil {
+		b.Fatalf("Expected to build the tree, got %s", err)
+	}
+
+	b.ResetTimer()
+	return trie
+}
+
+// Benchmarks a fuzzy search for 'Som' with bigram signatures
+func BenchmarkFuzzySomBigrams(b *testing.B) {
+
+	trie := buildIntegrationTreeBigrams(b)
+
+	for i := 0; i < b.N; i++ {
+		trie.FuzzySearch("som")
+	}
+}
+
+// Benchmarks a fuzzy search for 'Somer' with bigram signatures
+func BenchmarkFuzzySomerBigrams(b *testing.B) {
+
+	trie := buildIntegrationTreeBigrams(b)
+
+	for i := 0; i < b.N; i++ {
+		trie.FuzzySearch("somer")
+	}
+}
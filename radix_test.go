@@ -2,9 +2,11 @@ package radix
 
 import (
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"testing"
+	"unicode/utf8"
 )
 
 // Used for the data assigned to the struct for something to compare
@@ -75,13 +77,13 @@ func getWikipediaExampleTree() *RadixTree {
 									key: []byte("e"),
 									children: []*radixNode{
 										{
-											key:       []byte("r"),
-											content:   identifier{"ruber"},
+											key:       []byte("ns"),
+											content:   identifier{"rubens"},
 											doCollect: true,
 										},
 										{
-											key:       []byte("ns"),
-											content:   identifier{"rubens"},
+											key:       []byte("r"),
+											content:   identifier{"ruber"},
 											doCollect: true,
 										},
 									},
@@ -132,8 +134,8 @@ func TestDrawVisualisation(t *testing.T) {
 		`   |`,
 		`   +- [e]`,
 		`      |`,
-		`      +- [r]`,
 		`      +- [ns]`,
+		`      +- [r]`,
 		`   +- [ic]`,
 		`      |`,
 		`      +- [on]`,
@@ -385,8 +387,8 @@ func TestPrefixSearch(t *testing.T) {
 			"romane",
 			"romanus",
 			"romulus",
-			"ruber",
 			"rubens",
+			"ruber",
 			"rubicon",
 			"rubicundus",
 		}
@@ -407,8 +409,8 @@ func TestPrefixSearch(t *testing.T) {
 			"romane",
 			"romanus",
 			"romulus",
-			"ruber",
 			"rubens",
+			"ruber",
 			"rubicon",
 			"rubicundus",
 		}
@@ -451,14 +453,14 @@ func TestPrefixSearchDiffByOne(t *testing.T) {
 func TestAddBitMaskSet(t *testing.T) {
 
 	r := NewRadixTree()
-	root := r.Add("november", identifier{"november"})
+	root, _ := r.Add("november", identifier{"november"})
 	r.Add("nova", identifier{"nova"})
 	r.Add("niagra falls", identifier{"falls"})
 	r.Add("noel", identifier{"noel"})
 
 	// The first node should the letters below n
 	{
-		expected := genBitMask([]byte{
+		expected := genBitMask32([]byte{
 			'n', 'o', 'v', 'e', 'm', 'b', 'r',
 			'a',
 			'i', 'g', ' ', 'f', 'l', 's'})
@@ -474,9 +476,9 @@ func TestAddBitMaskSet(t *testing.T) {
 	// The letters below 'o' should contain everything but those in
 	// niagra falls
 	{
-		node := root.Children()[0]
+		node := root.Children()[1]
 
-		expected := genBitMask([]byte{
+		expected := genBitMask32([]byte{
 			'o', 'v', 'e', 'm', 'b', 'r',
 			'a', 'l'})
 
@@ -490,9 +492,9 @@ func TestAddBitMaskSet(t *testing.T) {
 
 	// The next node down should be a v, which drops the l and o
 	{
-		node := root.Children()[0].Children()[0]
+		node := root.Children()[1].Children()[1]
 
-		expected := genBitMask([]byte{
+		expected := genBitMask32([]byte{
 			'v', 'e', 'm', 'b', 'r', 'a'})
 
 		if node.BitMask() != expected {
@@ -505,9 +507,9 @@ func TestAddBitMaskSet(t *testing.T) {
 
 	// The next node down should be a ember, which has no children
 	{
-		node := root.Children()[0].Children()[0].Children()[0]
+		node := root.Children()[1].Children()[1].Children()[1]
 
-		expected := genBitMask([]byte{
+		expected := genBitMask32([]byte{
 			'e', 'm', 'b', 'r'})
 
 		if node.BitMask() != expected {
@@ -605,8 +607,8 @@ func TestFuzzySearch(t *testing.T) {
 			"romane",
 			"romanus",
 			"romulus",
-			"ruber",
 			"rubens",
+			"ruber",
 			"rubicon",
 			"rubicundus",
 		}
@@ -654,15 +656,14 @@ func TestFuzzyIntegration(t *testing.T) {
 			Search: "pablo iglesia",
 			Expect: "avenida de pablo iglesias, alcobendas",
 		},
-		// Removed while testing string bytes is annoying
-		// @TODO(mark): Re-integrate and add more tests like this
-		// {
-		// 	Search: "madrid",
-		// 	//                  +- Normally an á here, removed from bytes
-		// 	//                  |
-		// 	//                  v
-		// 	Expect: "calle de berstegui, pueblo nuevo, madrid",
-		// },
+		{
+			Search: "tesorería madrid",
+			Expect: "tesorería general de la seguridad social, madrid",
+		},
+		{
+			Search: "alcalá",
+			Expect: "ecowood, alcalá de henares",
+		},
 		{
 			Search: "se1",
 			Expect: "se1 1ab",
@@ -716,7 +717,7 @@ func TestFuzzyIntegrationNotExpected(t *testing.T) {
 	}
 }
 
-func testLongestPrefix(t *testing.T) {
+func TestLongestPrefix(t *testing.T) {
 
 	// Grab pre-created tree
 	r := NewRadixTree()
@@ -776,3 +777,594 @@ func testLongestPrefix(t *testing.T) {
 		}
 	}
 }
+
+// Builds the wikipedia example tree through Add, so that the bit masks and
+// counts are all set up as they would be normally
+func buildWikipediaExampleTree() *RadixTree {
+
+	r := NewRadixTree()
+	r.Add("romane", identifier{"romane"})
+	r.Add("romanus", identifier{"romanus"})
+	r.Add("romulus", identifier{"romulus"})
+	r.Add("ruber", identifier{"ruber"})
+	r.Add("rubens", identifier{"rubens"})
+	r.Add("rubicon", identifier{"rubicon"})
+	r.Add("rubicundus", identifier{"rubicundus"})
+
+	return r
+}
+
+// Deleting a leaf should leave its sibling merged back into the parent
+func TestDelete(t *testing.T) {
+
+	r := buildWikipediaExampleTree()
+
+	if !r.Delete("romane") {
+		t.Fatalf("Expected romane to be deleted")
+	}
+
+	expect := strings.Join([]string{
+		``,
+		`[r]`,
+		`|`,
+		`+- [om]`,
+		`   |`,
+		`   +- [anus]`,
+		`   +- [ulus]`,
+		`+- [ub]`,
+		`   |`,
+		`   +- [e]`,
+		`      |`,
+		`      +- [ns]`,
+		`      +- [r]`,
+		`   +- [ic]`,
+		`      |`,
+		`      +- [on]`,
+		`      +- [undus]`,
+		``,
+	}, "\n")
+
+	if r.String() != expect {
+		t.Errorf("Result %s does not match expected %s", r.String(), expect)
+	}
+
+	keys, content := r.PrefixSearch("rom")
+	expected := []string{"romanus", "romulus"}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Prefix result %+v does not matched expected %+v",
+			keys, expected)
+	}
+	compareKeysAndContent(keys, content, t)
+
+	if r.stringCount != 6 {
+		t.Errorf("Expected string count of 6, got %d", r.stringCount)
+	}
+
+	// Should have the same number of nodes as a tree built without it
+	rebuilt := NewRadixTree()
+	for _, key := range []string{
+		"romanus", "romulus", "ruber", "rubens", "rubicon", "rubicundus",
+	} {
+		rebuilt.Add(key, identifier{key})
+	}
+	if r.nodeCount != rebuilt.nodeCount {
+		t.Errorf("Expected node count of %d, got %d",
+			rebuilt.nodeCount, r.nodeCount)
+	}
+
+	// Can't delete it twice, or delete something that is only a prefix
+	if r.Delete("romane") {
+		t.Errorf("Expected romane to not be deleted a second time")
+	}
+	if r.Delete("rom") {
+		t.Errorf("Expected rom to not be deleted as it was never added")
+	}
+}
+
+// Deleting a key which has children should only stop it from being
+// collected, and a parent left with one child merges with it
+func TestDeleteInnerKey(t *testing.T) {
+
+	r := NewRadixTree()
+	r.Add("rabbit", identifier{"rabbit"})
+	r.Add("rabbi", identifier{"rabbi"})
+	r.Add("rab", identifier{"rab"})
+
+	if !r.Delete("rabbi") {
+		t.Fatalf("Expected rabbi to be deleted")
+	}
+
+	expect := strings.Join([]string{
+		``,
+		`[rab]`,
+		`|`,
+		`+- [bit]`,
+		``,
+	}, "\n")
+
+	if r.String() != expect {
+		t.Errorf("Result %s does not match expected %s", r.String(), expect)
+	}
+
+	keys, content := r.PrefixSearch("rab")
+	expected := []string{"rab", "rabbit"}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Prefix result %+v does not matched expected %+v",
+			keys, expected)
+	}
+	compareKeysAndContent(keys, content, t)
+
+	if r.nodeCount != 2 {
+		t.Errorf("Expected node count of 2, got %d", r.nodeCount)
+	}
+}
+
+// Bit masks should no longer contain the letters of deleted keys
+func TestDeleteBitMask(t *testing.T) {
+
+	r := buildWikipediaExampleTree()
+	r.Delete("romulus")
+
+	node := r.root.Children()[0]
+	expected := genBitMask32([]byte("romanusbeicd"))
+
+	if node.BitMask() != expected {
+		expectedStr := strconv.FormatInt(int64(expected), 2)
+		gotStr := strconv.FormatInt(int64(node.BitMask()), 2)
+		t.Errorf("Bitmask %s did not match %s", gotStr, expectedStr)
+	}
+
+	keys, _ := r.FuzzySearch("l")
+	if len(keys) != 0 {
+		t.Errorf("Expected no results for 'l', got %+v", keys)
+	}
+}
+
+// Test removing whole branches of the tree
+func TestDeletePrefix(t *testing.T) {
+
+	r := buildWikipediaExampleTree()
+
+	if removed := r.DeletePrefix("rubi"); removed != 2 {
+		t.Errorf("Expected 2 keys to be removed, got %d", removed)
+	}
+
+	keys, content := r.PrefixSearch("")
+	expected := []string{"romane", "romanus", "romulus", "rubens", "ruber"}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Prefix result %+v does not matched expected %+v",
+			keys, expected)
+	}
+	compareKeysAndContent(keys, content, t)
+
+	// The 'ub' node now only has 'e' below, so they become one
+	expect := strings.Join([]string{
+		``,
+		`[r]`,
+		`|`,
+		`+- [om]`,
+		`   |`,
+		`   +- [an]`,
+		`      |`,
+		`      +- [e]`,
+		`      +- [us]`,
+		`   +- [ulus]`,
+		`+- [ube]`,
+		`   |`,
+		`   +- [ns]`,
+		`   +- [r]`,
+		``,
+	}, "\n")
+
+	if r.String() != expect {
+		t.Errorf("Result %s does not match expected %s", r.String(), expect)
+	}
+
+	if r.stringCount != 5 {
+		t.Errorf("Expected string count of 5, got %d", r.stringCount)
+	}
+
+	// A prefix ending part way through a node takes the node with it
+	if removed := r.DeletePrefix("ro"); removed != 3 {
+		t.Errorf("Expected 3 keys to be removed, got %d", removed)
+	}
+	if removed := r.DeletePrefix("x"); removed != 0 {
+		t.Errorf("Expected nothing to be removed, got %d", removed)
+	}
+
+	keys, _ = r.PrefixSearch("")
+	expected = []string{"rubens", "ruber"}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Prefix result %+v does not matched expected %+v",
+			keys, expected)
+	}
+
+	// Empty prefix clears the lot
+	if removed := r.DeletePrefix(""); removed != 2 {
+		t.Errorf("Expected 2 keys to be removed, got %d", removed)
+	}
+	if r.stringCount != 0 || r.nodeCount != 0 {
+		t.Errorf("Expected empty tree, got %d strings and %d nodes",
+			r.stringCount, r.nodeCount)
+	}
+}
+
+// Exact lookups should only find keys which were added
+func TestGet(t *testing.T) {
+
+	r := buildWikipediaExampleTree()
+
+	testCases := []struct {
+		Key   string
+		Found bool
+	}{
+		{Key: "romane", Found: true},
+		{Key: "rubicundus", Found: true},
+		{Key: "ruber", Found: true},
+		{Key: "rom", Found: false},
+		{Key: "rubi", Found: false},
+		{Key: "romanes", Found: false},
+		{Key: "x", Found: false},
+		{Key: "", Found: false},
+	}
+
+	for _, test := range testCases {
+
+		content, ok := r.Get(test.Key)
+		if ok != test.Found {
+			t.Errorf("Expected found to be %t for %s, got %t",
+				test.Found, test.Key, ok)
+			continue
+		}
+
+		if ok && content != (identifier{test.Key}) {
+			t.Errorf("Expected content for %s, got %+v", test.Key, content)
+		}
+
+		if r.Contains(test.Key) != test.Found {
+			t.Errorf("Expected contains to be %t for %s",
+				test.Found, test.Key)
+		}
+	}
+}
+
+// Update should modify existing content and insert missing keys
+func TestUpdate(t *testing.T) {
+
+	r := NewRadixTree()
+	r.Add("romane", 1)
+
+	increment := func(content interface{}, exists bool) interface{} {
+		if !exists {
+			return 1
+		}
+		return content.(int) + 1
+	}
+
+	r.Update("romane", increment)
+	r.Update("romanus", increment)
+	r.Update("romanus", increment)
+	r.Update("romanus", increment)
+
+	if content, _ := r.Get("romane"); content != 2 {
+		t.Errorf("Expected romane to be 2, got %+v", content)
+	}
+	if content, _ := r.Get("romanus"); content != 3 {
+		t.Errorf("Expected romanus to be 3, got %+v", content)
+	}
+	if r.stringCount != 2 {
+		t.Errorf("Expected string count of 2, got %d", r.stringCount)
+	}
+}
+
+// Adding the same key again should replace the content, not add another
+func TestAddDuplicate(t *testing.T) {
+
+	r := NewRadixTree()
+
+	if _, isNew := r.Add("rab", identifier{"wrong"}); !isNew {
+		t.Errorf("Expected rab to be new")
+	}
+	if _, isNew := r.Add("rabbit", identifier{"rabbit"}); !isNew {
+		t.Errorf("Expected rabbit to be new")
+	}
+	if _, isNew := r.Add("rab", identifier{"rab"}); isNew {
+		t.Errorf("Expected rab to already exist")
+	}
+
+	keys, content := r.PrefixSearch("")
+	expected := []string{"rab", "rabbit"}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Prefix result %+v does not matched expected %+v",
+			keys, expected)
+	}
+	compareKeysAndContent(keys, content, t)
+
+	if r.Len() != 2 || r.nodeCount != 2 {
+		t.Errorf("Expected 2 strings and 2 nodes, got %d and %d",
+			r.Len(), r.nodeCount)
+	}
+	if r.root.Collect() {
+		t.Errorf("Root should never be collected")
+	}
+}
+
+// AddIfAbsent and Replace only act when the key is missing or present
+func TestAddIfAbsentAndReplace(t *testing.T) {
+
+	r := NewRadixTree()
+
+	if !r.AddIfAbsent("rabbit", identifier{"rabbit"}) {
+		t.Errorf("Expected rabbit to be added")
+	}
+	if r.AddIfAbsent("rabbit", identifier{"wrong"}) {
+		t.Errorf("Expected rabbit to not be added a second time")
+	}
+	if r.Replace("rabbi", identifier{"rabbi"}) {
+		t.Errorf("Expected rabbi to not be replaced as it doesn't exist")
+	}
+	if r.Contains("rabbi") {
+		t.Errorf("Replace should not insert rabbi")
+	}
+
+	r.Add("rabbi", identifier{"wrong"})
+	if !r.Replace("rabbi", identifier{"rabbi"}) {
+		t.Errorf("Expected rabbi to be replaced")
+	}
+
+	keys, content := r.PrefixSearch("")
+	compareKeysAndContent(keys, content, t)
+
+	if r.Len() != 2 {
+		t.Errorf("Expected 2 strings, got %d", r.Len())
+	}
+}
+
+// The integration tree contains repeated addresses, they should only be
+// counted once and come back once
+func TestAddIntegrationCount(t *testing.T) {
+
+	r := buildIntegrationTree()
+	keys, _ := r.PrefixSearch("")
+
+	if r.Len() != len(keys) {
+		t.Errorf("Expected %d strings, got %d", len(keys), r.Len())
+	}
+
+	seen := map[string]bool{}
+	for _, key := range keys {
+		if seen[key] {
+			t.Errorf("Key %s was returned more than once", key)
+		}
+		seen[key] = true
+	}
+}
+
+// A typed tree should give content back without any type assertions
+func TestTypedTree(t *testing.T) {
+
+	r := NewTree[int]()
+	r.Add("romane", 1)
+	r.Add("romanus", 2)
+	r.Add("romulus", 3)
+
+	keys, content := r.PrefixSearch("roman")
+	if !reflect.DeepEqual(keys, []string{"romane", "romanus"}) ||
+		!reflect.DeepEqual(content, []int{1, 2}) {
+		t.Errorf("Prefix result %+v %+v does not match expected", keys, content)
+	}
+
+	if value, ok := r.Get("romulus"); !ok || value != 3 {
+		t.Errorf("Expected romulus to be 3, got %d", value)
+	}
+
+	r.Delete("romulus")
+	if value, ok := r.Get("romulus"); ok || value != 0 {
+		t.Errorf("Expected romulus to be gone, got %d", value)
+	}
+}
+
+// Keys outside of ASCII should be stored and found as they are
+func TestUnicodeKeys(t *testing.T) {
+
+	r := NewRadixTree()
+	for _, key := range []string{
+		"tesorería",
+		"tesorero",
+		"tesoro",
+		"καλημέρα",
+		"καλησπέρα",
+		"ことば",
+		"ことわざ",
+		"ã©",
+	} {
+		r.Add(key, identifier{key})
+	}
+
+	prefixCases := []struct {
+		Search string
+		Expect []string
+	}{
+		{Search: "tesorer", Expect: []string{"tesorero", "tesorería"}},
+		{Search: "tesorerí", Expect: []string{"tesorería"}},
+		{Search: "καλη", Expect: []string{"καλημέρα", "καλησπέρα"}},
+		{Search: "こと", Expect: []string{"ことば", "ことわざ"}},
+		{Search: "ことわ", Expect: []string{"ことわざ"}},
+	}
+
+	for _, test := range prefixCases {
+		keys, content := r.PrefixSearch(test.Search)
+		if !reflect.DeepEqual(keys, test.Expect) {
+			t.Errorf("Prefix result %+v for %s does not match expected %+v",
+				keys, test.Search, test.Expect)
+		}
+		compareKeysAndContent(keys, content, t)
+	}
+
+	fuzzyCases := []struct {
+		Search string
+		Expect []string
+	}{
+		{Search: "tría", Expect: []string{"tesorería"}},
+		{Search: "μέρα", Expect: []string{"καλημέρα"}},
+		{Search: "とざ", Expect: []string{"ことわざ"}},
+
+		// The bytes of é can be found across ã and ©, but not the letter
+		{Search: "é", Expect: []string{}},
+	}
+
+	for _, test := range fuzzyCases {
+		keys, content := r.FuzzySearch(test.Search)
+		if !reflect.DeepEqual(keys, test.Expect) {
+			t.Errorf("Fuzzy result %+v for %s does not match expected %+v",
+				keys, test.Search, test.Expect)
+		}
+		compareKeysAndContent(keys, content, t)
+	}
+
+	if p, ok := r.LongestPrefix("ことばかり"); !ok || p != "ことば" {
+		t.Errorf("Expected longest prefix ことば, got %s", p)
+	}
+	if !r.Contains("καλημέρα") || r.Contains("καλημ") {
+		t.Errorf("Expected only whole keys to be contained")
+	}
+
+	// Splits should only ever fall between whole letters
+	r.root.WalkDepthFirst(func(
+		key []byte, depth int, first bool, last bool, children int,
+	) terminate {
+		if !utf8.Valid(key) {
+			t.Errorf("Node key %q is not valid UTF-8", key)
+		}
+		return terminate(false)
+	}, 0)
+}
+
+// Longest prefix should find the longest key, not just the deepest node
+func TestLongestPrefixNested(t *testing.T) {
+
+	r := NewRadixTree()
+	r.Add("+44", struct{}{})
+	r.Add("+442", struct{}{})
+	r.Add("+4423", struct{}{})
+
+	testCases := []struct {
+		Search string
+		Expect string
+		Found  bool
+	}{
+		{Search: "+4424", Expect: "+442", Found: true},
+		{Search: "+44231", Expect: "+4423", Found: true},
+		{Search: "+441", Expect: "+44", Found: true},
+		{Search: "+4", Expect: "", Found: false},
+	}
+
+	for _, test := range testCases {
+		p, ok := r.LongestPrefix(test.Search)
+		if p != test.Expect || ok != test.Found {
+			t.Errorf("Expected longest prefix of %s to be %s (%t), got %s (%t)",
+				test.Search, test.Expect, test.Found, p, ok)
+		}
+	}
+}
+
+// Limits and offsets should page through the same results as the
+// unbounded search
+func TestSearchWithOptions(t *testing.T) {
+
+	r := buildWikipediaExampleTree()
+
+	{
+		keys, content := r.PrefixSearchWithOptions("r", SearchOptions{
+			Limit:  2,
+			Offset: 3,
+		})
+		expected := []string{"rubens", "ruber"}
+		if !reflect.DeepEqual(keys, expected) {
+			t.Errorf("Prefix result %+v does not matched expected %+v",
+				keys, expected)
+		}
+		compareKeysAndContent(keys, content, t)
+	}
+
+	{
+		keys, content := r.FuzzySearchWithOptions("us", SearchOptions{
+			Limit: 3,
+		})
+		expected := []string{"romanus", "romulus", "rubens"}
+		if !reflect.DeepEqual(keys, expected) {
+			t.Errorf("Fuzzy result %+v does not matched expected %+v",
+				keys, expected)
+		}
+		compareKeysAndContent(keys, content, t)
+	}
+
+	{
+		keys, _ := r.PrefixSearchWithOptions("r", SearchOptions{Offset: 10})
+		if len(keys) != 0 {
+			t.Errorf("Expected no results past the end, got %+v", keys)
+		}
+	}
+
+	{
+		negative := SearchOptions{Limit: -1, Offset: -1}
+		if keys, _ := r.PrefixSearchWithOptions("r", negative); len(keys) != 7 {
+			t.Errorf("Expected negative options to be ignored, got %+v", keys)
+		}
+		if keys, _ := r.FuzzySearchWithOptions("us", negative); len(keys) != 4 {
+			t.Errorf("Expected negative options to be ignored, got %+v", keys)
+		}
+	}
+
+	// Page through the integration tree
+	integration := buildIntegrationTree()
+	for _, search := range []func(string, SearchOptions) ([]string, []interface{}){
+		integration.PrefixSearchWithOptions,
+		integration.FuzzySearchWithOptions,
+	} {
+		all, _ := search("s", SearchOptions{})
+		paged := []string{}
+
+		for offset := 0; ; offset += 1000 {
+			page, _ := search("s", SearchOptions{Limit: 1000, Offset: offset})
+			if len(page) > 1000 {
+				t.Fatalf("Page of %d results exceeds the limit", len(page))
+			}
+			if len(page) == 0 {
+				break
+			}
+			paged = append(paged, page...)
+		}
+
+		if !reflect.DeepEqual(all, paged) {
+			t.Errorf("Paged results (%d) do not match all results (%d)",
+				len(paged), len(all))
+		}
+	}
+}
+
+// The order keys are added in should make no difference, they always come
+// back in lexicographic order
+func TestSortedChildren(t *testing.T) {
+
+	keys := []string{
+		"rubicundus", "romulus", "ruber", "romanus", "rubicon", "rubens",
+		"romane",
+	}
+
+	r := NewRadixTree()
+	for _, key := range keys {
+		r.Add(key, identifier{key})
+	}
+
+	expected := buildWikipediaExampleTree()
+	if r.String() != expected.String() {
+		t.Errorf("Result %s does not match expected %s",
+			r.String(), expected.String())
+	}
+
+	all, _ := buildIntegrationTree().PrefixSearch("")
+	if !sort.StringsAreSorted(all) {
+		t.Errorf("Expected integration tree keys to be sorted")
+	}
+}
@@ -0,0 +1,175 @@
+package radix
+
+import (
+	"sort"
+	"unicode/utf8"
+)
+
+// Weightings used when scoring a fuzzy match, the higher the score the better
+// the match
+const (
+	fuzzyPrefixBonus   = 100
+	fuzzyGapPenalty    = 4
+	fuzzyStartPenalty  = 1
+	fuzzyLengthPenalty = 1
+)
+
+// FuzzyResult is a single key found by a fuzzy search, along with how well it
+// matched. Positions are in bytes from the start of the key, and are those of
+// the closest match where the search appears more than once
+type FuzzyResult[V any] struct {
+	Key     string
+	Content V
+
+	// Score combines the below, higher is better
+	Score int
+
+	// Where the first letter of the search was matched, 0 means the search
+	// matched as a prefix
+	Start int
+
+	// The number of bytes from the first matched letter to the last
+	Span int
+
+	// The number of bytes within the span which weren't part of the search
+	Gaps int
+}
+
+// FuzzySearchRanked performs the same search as FuzzySearch, but each result
+// is scored on how closely it matched and they are returned best first. Keys
+// which matched as a prefix, had fewer letters between those searched for and
+// are shorter come first. The options page through the results from the
+// best, though every result has to be found to know which those are
+func (tree *Tree[V]) FuzzySearchRanked(
+	str string,
+	opts SearchOptions,
+) []FuzzyResult[V] {
+
+	if len(tree.root.Children()) == 0 || str == "" {
+		return []FuzzyResult[V]{}
+	}
+
+	query := tree.stringToBytes(str)
+	results := []FuzzyResult[V]{}
+	tree.fuzzySearch(
+		query,
+		tree.root,
+		0,
+		0,
+		-1,
+		-1,
+		[]byte{},
+		func(result FuzzyResult[V]) bool {
+			results = append(results, result)
+			return true
+		})
+
+	// The search matches each letter as early as it can, which isn't always
+	// the closest match in the key
+	for i := range results {
+		key := tree.stringToBytes(results[i].Key)
+		results[i].Start, results[i].Span = bestMatch(key, query)
+		results[i].Gaps = results[i].Span - len(query)
+		results[i].Score = results[i].score()
+	}
+
+	// Stable so that equal scores stay in the order of the tree
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	// Everything has to be found to know which are best, but only the page
+	// asked for is returned
+	opts = opts.clamped()
+	if opts.Offset >= len(results) {
+		return []FuzzyResult[V]{}
+	}
+	results = results[opts.Offset:]
+
+	if opts.Limit > 0 && len(results) > opts.Limit {
+		results = results[:opts.Limit]
+	}
+
+	return results
+}
+
+// Works out the score for a result from its match positions
+func (fr FuzzyResult[V]) score() int {
+
+	score := 0
+	if fr.Start == 0 {
+		score += fuzzyPrefixBonus
+	}
+
+	score -= fr.Gaps * fuzzyGapPenalty
+	score -= fr.Start * fuzzyStartPenalty
+	score -= len(fr.Key) * fuzzyLengthPenalty
+
+	return score
+}
+
+// Finds the tightest match of the letters of the query in order within the
+// key, returning its start and span. For each place the first letter
+// matches, the rest are matched as early as they can be, which gives the
+// tightest match starting there. The earliest of the tightest wins
+func bestMatch(key []byte, query []byte) (int, int) {
+
+	want, _ := utf8.DecodeRune(query)
+	bestStart, bestSpan := -1, 0
+
+	for start := 0; start < len(key); {
+
+		first, size := utf8.DecodeRune(key[start:])
+
+		if first == want {
+			end, ok := matchFrom(key, query, start)
+
+			// Nothing later can match either
+			if !ok {
+				break
+			}
+
+			if bestStart < 0 || end-start < bestSpan {
+				bestStart, bestSpan = start, end-start
+			}
+		}
+
+		start += size
+	}
+
+	// Always found, as the search has matched the key already
+	if bestStart < 0 {
+		return 0, len(key)
+	}
+
+	return bestStart, bestSpan
+}
+
+// Matches the letters of the query in order from the start, as early as they
+// can be. Returns where the last one ends
+func matchFrom(key []byte, query []byte, start int) (int, bool) {
+
+	index := start
+
+	for len(query) > 0 {
+
+		want, wantSize := utf8.DecodeRune(query)
+
+		for {
+			if index >= len(key) {
+				return 0, false
+			}
+
+			r, size := utf8.DecodeRune(key[index:])
+			index += size
+
+			if r == want {
+				break
+			}
+		}
+
+		query = query[wantSize:]
+	}
+
+	return index, true
+}
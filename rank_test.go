@@ -0,0 +1,125 @@
+package radix
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Results should come back with the closest matches first
+func TestFuzzySearchRanked(t *testing.T) {
+
+	r := NewRadixTree()
+	r.Add("a street of many odd markers", identifier{"a street of many odd markers"})
+	r.Add("somerset", identifier{"somerset"})
+	r.Add("handsome road", identifier{"handsome road"})
+	r.Add("somerset road", identifier{"somerset road"})
+
+	results := r.FuzzySearchRanked("som", SearchOptions{})
+
+	keys := []string{}
+	for _, result := range results {
+		keys = append(keys, result.Key)
+		if result.Content != (identifier{result.Key}) {
+			t.Errorf("Key %s did not return valid content", result.Key)
+		}
+	}
+
+	expected := []string{
+		"somerset",
+		"somerset road",
+		"handsome road",
+		"a street of many odd markers",
+	}
+
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Ranked result %+v does not match expected %+v",
+			keys, expected)
+	}
+
+	// Check the positions that the score is built from
+	{
+		expected := FuzzyResult[interface{}]{
+			Key:     "handsome road",
+			Content: identifier{"handsome road"},
+			Start:   4,
+			Span:    3,
+			Gaps:    0,
+		}
+		expected.Score = expected.score()
+
+		if results[2] != expected {
+			t.Errorf("Result %+v does not match expected %+v",
+				results[2], expected)
+		}
+	}
+
+	{
+		result := results[3]
+		if result.Start != 2 || result.Span != 11 || result.Gaps != 8 {
+			t.Errorf("Unexpected positions for %s: %+v", result.Key, result)
+		}
+	}
+
+	limited := r.FuzzySearchRanked("som", SearchOptions{Limit: 2})
+	if len(limited) != 2 || limited[0].Key != "somerset" {
+		t.Errorf("Expected the best 2 results, got %+v", limited)
+	}
+
+	// Negative offsets and limits are treated as 0, as with the other searches
+	negative := r.FuzzySearchRanked("som", SearchOptions{Limit: -1, Offset: -1})
+	if !reflect.DeepEqual(negative, results) {
+		t.Errorf("Expected a negative offset to skip nothing, got %+v", negative)
+	}
+}
+
+// Ranking should bring the obvious match to the top of the integration tree
+func TestFuzzySearchRankedIntegration(t *testing.T) {
+
+	r := buildIntegrationTree()
+	results := r.FuzzySearchRanked("somerset", SearchOptions{Limit: 5})
+
+	if len(results) == 0 || results[0].Start != 0 {
+		t.Fatalf("Expected a prefix match first, got %+v", results)
+	}
+
+	for i := 1; i < len(results); i++ {
+		if results[i].Score > results[i-1].Score {
+			t.Errorf("Results are not sorted best first: %+v", results)
+		}
+	}
+}
+
+// The positions should be of the closest match in the key, not where the
+// first letter happens to appear first
+func TestFuzzySearchRankedClosestMatch(t *testing.T) {
+
+	r := NewTree[int]()
+	r.Add("salisbury old mill", 1)
+	r.Add("sussex road, somerset", 2)
+	r.Add("sxoxm som", 3)
+
+	results := r.FuzzySearchRanked("som", SearchOptions{})
+
+	expected := []struct {
+		Key   string
+		Start int
+		Span  int
+		Gaps  int
+	}{
+		{Key: "sxoxm som", Start: 6, Span: 3, Gaps: 0},
+		{Key: "sussex road, somerset", Start: 13, Span: 3, Gaps: 0},
+		{Key: "salisbury old mill", Start: 4, Span: 11, Gaps: 8},
+	}
+
+	if len(results) != len(expected) {
+		t.Fatalf("Expected %d results, got %+v", len(expected), results)
+	}
+
+	for i, result := range results {
+		if result.Key != expected[i].Key || result.Start != expected[i].Start ||
+			result.Span != expected[i].Span || result.Gaps != expected[i].Gaps {
+			t.Errorf("Expected result %d to be %+v, got %+v",
+				i, expected[i], result)
+		}
+	}
+}
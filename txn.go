@@ -0,0 +1,120 @@
+package radix
+
+import "errors"
+
+var (
+	// ErrTxnClosed is returned when committing a transaction which has
+	// already been committed or aborted
+	ErrTxnClosed = errors.New("Transaction has already been closed")
+
+	// ErrTxnConflict is returned when committing a transaction after the
+	// tree has been changed outside of it
+	ErrTxnConflict = errors.New("Tree has changed since the transaction began")
+)
+
+// Txn stages a batch of changes to a tree so that they are applied all at
+// once or not at all. Changes are made to a snapshot of the tree, copying
+// only the nodes along their paths, and the tree is left untouched until
+// the transaction is committed
+type Txn[V any] struct {
+	tree   *Tree[V]
+	base   *treeNode[V]
+	staged *Tree[V]
+}
+
+// Txn begins a transaction against the tree
+func (tree *Tree[V]) Txn() *Txn[V] {
+	return &Txn[V]{
+		tree:   tree,
+		base:   tree.root,
+		staged: tree.Snapshot(),
+	}
+}
+
+// Add stages inserting a key, replacing the content if it exists. Returns
+// whether the key was new
+func (txn *Txn[V]) Add(str string, content V) bool {
+
+	if txn.staged == nil {
+		return false
+	}
+
+	_, isNew := txn.staged.Add(str, content)
+	return isNew
+}
+
+// Delete stages removing a key, returning whether it was there to be removed
+func (txn *Txn[V]) Delete(str string) bool {
+
+	if txn.staged == nil {
+		return false
+	}
+
+	return txn.staged.Delete(str)
+}
+
+// DeletePrefix stages removing every key beginning with the prefix,
+// returning the number of keys which will be removed
+func (txn *Txn[V]) DeletePrefix(str string) int {
+
+	if txn.staged == nil {
+		return 0
+	}
+
+	return txn.staged.DeletePrefix(str)
+}
+
+// Get returns the content of a key as it stands with the staged changes
+func (txn *Txn[V]) Get(str string) (V, bool) {
+
+	if txn.staged == nil {
+		var zero V
+		return zero, false
+	}
+
+	return txn.staged.Get(str)
+}
+
+// Len returns the number of keys there will be once committed
+func (txn *Txn[V]) Len() int {
+
+	if txn.staged == nil {
+		return 0
+	}
+
+	return txn.staged.Len()
+}
+
+// Commit applies every staged change to the tree at once. If the tree has
+// been changed since the transaction began nothing is applied, as doing so
+// would undo those changes. Either way the transaction is closed
+func (txn *Txn[V]) Commit() error {
+
+	if txn.staged == nil {
+		return ErrTxnClosed
+	}
+
+	staged := txn.staged
+	txn.staged = nil
+
+	// Any change to the tree gives it a new root, as the one it had is
+	// shared with the transaction
+	if txn.tree.root != txn.base {
+		return ErrTxnConflict
+	}
+
+	// Nothing else holds the transaction's nodes, so the tree takes over
+	// its generation and can keep changing them in place
+	txn.tree.root = staged.root
+	txn.tree.stringCount = staged.stringCount
+	txn.tree.nodeCount = staged.nodeCount
+	txn.tree.generation = staged.generation
+
+	return nil
+}
+
+// Abort throws away the staged changes, leaving the tree as it was. Changes
+// made after a transaction is closed have no effect
+func (txn *Txn[V]) Abort() {
+	txn.staged = nil
+}
@@ -0,0 +1,132 @@
+package radix
+
+import (
+	"slices"
+	"testing"
+)
+
+// Nothing staged should be seen until commit, then all of it at once
+func TestTxnCommit(t *testing.T) {
+
+	r := NewTree[int]()
+	r.Add("romane", 1)
+	r.Add("romanus", 2)
+	r.Add("romulus", 3)
+
+	txn := r.Txn()
+	if !txn.Add("rubens", 4) {
+		t.Errorf("Expected rubens to be new")
+	}
+	txn.Add("romane", 10)
+	if !txn.Delete("romanus") {
+		t.Errorf("Expected romanus to be removed")
+	}
+	if txn.Delete("ruber") {
+		t.Errorf("Expected ruber not to be removed")
+	}
+
+	if value, _ := txn.Get("romane"); value != 10 {
+		t.Errorf("Expected the transaction to see romane as 10, got %d", value)
+	}
+	if txn.Len() != 3 {
+		t.Errorf("Expected the transaction to hold 3 keys, got %d", txn.Len())
+	}
+
+	keys, _ := r.PrefixSearch("")
+	if !slices.Equal(keys, []string{"romane", "romanus", "romulus"}) {
+		t.Errorf("Expected the tree to be unchanged before commit, got %v", keys)
+	}
+
+	if err := txn.Commit(); err != nil {
+		t.Fatalf("Expected commit to succeed, got %s", err)
+	}
+
+	keys, content := r.PrefixSearch("")
+	if !slices.Equal(keys, []string{"romane", "romulus", "rubens"}) {
+		t.Errorf("Expected the committed keys, got %v", keys)
+	}
+	if !slices.Equal(content, []int{10, 3, 4}) {
+		t.Errorf("Expected the committed content, got %v", content)
+	}
+
+	// The counts should match a tree built from scratch
+	expected := NewTree[int]()
+	expected.Add("romane", 10)
+	expected.Add("romulus", 3)
+	expected.Add("rubens", 4)
+
+	if r.Len() != expected.Len() || r.nodeCount != expected.nodeCount {
+		t.Errorf("Expected %d keys and %d nodes, got %d and %d",
+			expected.Len(), expected.nodeCount, r.Len(), r.nodeCount)
+	}
+	if r.String() != expected.String() {
+		t.Errorf("Expected the committed tree to match:\n%s\ngot:\n%s",
+			expected.String(), r.String())
+	}
+
+	// The tree carries on as normal afterwards
+	r.Add("ruber", 5)
+	if r.Len() != 4 || !r.Contains("ruber") {
+		t.Errorf("Expected ruber to be added after commit")
+	}
+
+	if err := txn.Commit(); err != ErrTxnClosed {
+		t.Errorf("Expected committing twice to fail, got %v", err)
+	}
+}
+
+// Aborting leaves the tree as it was
+func TestTxnAbort(t *testing.T) {
+
+	r := NewTree[int]()
+	r.Add("romane", 1)
+
+	txn := r.Txn()
+	txn.Add("romanus", 2)
+	txn.DeletePrefix("rom")
+	txn.Abort()
+
+	if r.Len() != 1 || !r.Contains("romane") {
+		t.Errorf("Expected the tree to be unchanged after abort")
+	}
+
+	if txn.Add("rubens", 3) || r.Contains("rubens") {
+		t.Errorf("Expected adding after abort to have no effect")
+	}
+	if err := txn.Commit(); err != ErrTxnClosed {
+		t.Errorf("Expected committing after abort to fail, got %v", err)
+	}
+}
+
+// Changes made to the tree while a transaction is open would be lost by
+// committing it, so the commit is refused
+func TestTxnConflict(t *testing.T) {
+
+	r := NewTree[int]()
+	r.Add("romane", 1)
+
+	txn := r.Txn()
+	txn.Add("romanus", 2)
+	r.Add("romulus", 3)
+
+	if err := txn.Commit(); err != ErrTxnConflict {
+		t.Errorf("Expected a conflict, got %v", err)
+	}
+
+	keys, _ := r.PrefixSearch("")
+	if !slices.Equal(keys, []string{"romane", "romulus"}) {
+		t.Errorf("Expected the tree to keep its own changes, got %v", keys)
+	}
+
+	// Snapshots don't change the tree, so don't conflict
+	txn = r.Txn()
+	txn.Add("romanus", 2)
+	r.Snapshot()
+
+	if err := txn.Commit(); err != nil {
+		t.Errorf("Expected commit to succeed, got %s", err)
+	}
+	if r.Len() != 3 {
+		t.Errorf("Expected 3 keys, got %d", r.Len())
+	}
+}
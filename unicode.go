@@ -0,0 +1,41 @@
+package radix
+
+import "unicode/utf8"
+
+// The plain letter underlying each accented Latin letter, starting from
+// U+00C0 (À) up to U+017F (ſ). A '.' means the letter has no canonical
+// decomposition onto a plain letter (Æ, Ø, Ł and the like)
+const latinBases = "" +
+	"aaaaaa.ceeeeiiii.nooooo..uuuuy.." + // U+00C0 - U+00DF
+	"aaaaaa.ceeeeiiii.nooooo..uuuuy.y" + // U+00E0 - U+00FF
+	"aaaaaaccccccccdd..eeeeeeeeeegggg" + // U+0100 - U+011F
+	"gggghh..iiiiiiiii...jjkk.llllll." + // U+0120 - U+013F
+	"...nnnnnn...oooooo..rrrrrrssssss" + // U+0140 - U+015F
+	"sstttt..uuuuuuuuuuuuwwyyyzzzzzzs" //   U+0160 - U+017F
+
+// Returns the plain lower case letter an accented Latin letter is based on,
+// so 'á' and 'Á' both give 'a'
+func latinBase(r rune) (rune, bool) {
+
+	if r < 0xC0 || r > 0x17F {
+		return r, false
+	}
+
+	base := latinBases[r-0xC0]
+	if base == '.' {
+		return r, false
+	}
+
+	return rune(base), true
+}
+
+// Moves the index back to the start of the rune it sits within, used when
+// only part of a multi-byte letter has been matched
+func runeStartIndex(str []byte, index int) int {
+
+	for index > 0 && index < len(str) && !utf8.RuneStart(str[index]) {
+		index--
+	}
+
+	return index
+}
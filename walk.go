@@ -0,0 +1,67 @@
+package radix
+
+import "iter"
+
+// WalkPrefix calls the function for every key beginning with the prefix, in
+// the same order as PrefixSearch but without building up slices. Returning
+// false from the function stops the walk. The tree must not be changed from
+// within the function
+func (tree *Tree[V]) WalkPrefix(str string, fn func(key string, value V) bool) {
+
+	node, prefix, ok := tree.prefixSearch(
+		tree.stringToBytes(str),
+		tree.root,
+		0,
+		[]byte{})
+
+	if !ok {
+		return
+	}
+
+	tree.walk(node, prefix, func(key []byte, node *treeNode[V]) bool {
+		return fn(node.originalKey(key), node.Content())
+	})
+}
+
+// WalkFuzzy calls the function for every key matching the fuzzy search, in
+// the same order as FuzzySearch. Returning false from the function stops the
+// search. The tree must not be changed from within the function
+func (tree *Tree[V]) WalkFuzzy(str string, fn func(key string, value V) bool) {
+
+	if str == "" {
+		return
+	}
+
+	tree.fuzzySearch(
+		tree.stringToBytes(str),
+		tree.root,
+		0,
+		0,
+		-1,
+		-1,
+		[]byte{},
+		func(result FuzzyResult[V]) bool {
+			return fn(result.Key, result.Content)
+		})
+}
+
+// All returns an iterator over every key and its content
+func (tree *Tree[V]) All() iter.Seq2[string, V] {
+	return tree.Prefix("")
+}
+
+// Prefix returns an iterator over every key beginning with the prefix, keys
+// are found lazily as the iteration goes
+func (tree *Tree[V]) Prefix(str string) iter.Seq2[string, V] {
+	return func(yield func(string, V) bool) {
+		tree.WalkPrefix(str, yield)
+	}
+}
+
+// Fuzzy returns an iterator over every key matching the fuzzy search, keys
+// are found lazily as the iteration goes
+func (tree *Tree[V]) Fuzzy(str string) iter.Seq2[string, V] {
+	return func(yield func(string, V) bool) {
+		tree.WalkFuzzy(str, yield)
+	}
+}
@@ -0,0 +1,106 @@
+package radix
+
+import (
+	"iter"
+	"reflect"
+	"testing"
+)
+
+// Walking should give the same keys as the search, and stop when asked
+func TestWalkPrefix(t *testing.T) {
+
+	r := buildWikipediaExampleTree()
+
+	keys := []string{}
+	r.WalkPrefix("rub", func(key string, value interface{}) bool {
+		if value != (identifier{key}) {
+			t.Errorf("Key %s did not return valid content", key)
+		}
+		keys = append(keys, key)
+		return true
+	})
+
+	expected, _ := r.PrefixSearch("rub")
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Walk result %+v does not match expected %+v", keys, expected)
+	}
+
+	count := 0
+	r.WalkPrefix("", func(key string, value interface{}) bool {
+		count++
+		return count < 3
+	})
+	if count != 3 {
+		t.Errorf("Expected the walk to stop after 3 keys, got %d", count)
+	}
+
+	r.WalkPrefix("x", func(key string, value interface{}) bool {
+		t.Errorf("Expected no keys for 'x', got %s", key)
+		return true
+	})
+}
+
+// Iterators should behave as the searches do and allow breaking out early
+func TestIterators(t *testing.T) {
+
+	r := NewTree[int]()
+	r.Add("romane", 1)
+	r.Add("romanus", 2)
+	r.Add("romulus", 3)
+	r.Add("ruber", 4)
+
+	testCases := []struct {
+		Name   string
+		Keys   []string
+		Values []int
+		Got    func() ([]string, []int)
+	}{
+		{
+			Name:   "All",
+			Keys:   []string{"romane", "romanus", "romulus", "ruber"},
+			Values: []int{1, 2, 3, 4},
+			Got:    func() ([]string, []int) { return drain(r.All()) },
+		},
+		{
+			Name:   "Prefix",
+			Keys:   []string{"romane", "romanus"},
+			Values: []int{1, 2},
+			Got:    func() ([]string, []int) { return drain(r.Prefix("roman")) },
+		},
+		{
+			Name:   "Fuzzy",
+			Keys:   []string{"romanus", "romulus"},
+			Values: []int{2, 3},
+			Got:    func() ([]string, []int) { return drain(r.Fuzzy("us")) },
+		},
+	}
+
+	for _, test := range testCases {
+		keys, values := test.Got()
+		if !reflect.DeepEqual(keys, test.Keys) ||
+			!reflect.DeepEqual(values, test.Values) {
+			t.Errorf("%s gave %+v %+v, expected %+v %+v",
+				test.Name, keys, values, test.Keys, test.Values)
+		}
+	}
+
+	for key := range r.Fuzzy("r") {
+		if key != "romane" {
+			t.Errorf("Expected romane first, got %s", key)
+		}
+		break
+	}
+}
+
+// Reads everything from an iterator into slices
+func drain[V any](seq iter.Seq2[string, V]) ([]string, []V) {
+
+	keys := []string{}
+	values := []V{}
+	for key, value := range seq {
+		keys = append(keys, key)
+		values = append(values, value)
+	}
+
+	return keys, values
+}